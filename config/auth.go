@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Email verification policies, selected with EMAIL_VERIFICATION_POLICY
+const (
+	// VerificationPolicyNone lets unverified users log in and place orders
+	VerificationPolicyNone = "none"
+	// VerificationPolicyOrders lets unverified users log in but not place orders
+	VerificationPolicyOrders = "orders"
+	// VerificationPolicyLogin blocks unverified users from logging in
+	VerificationPolicyLogin = "login"
+)
+
+// EmailVerificationPolicy returns what unverified users are prevented from doing
+func EmailVerificationPolicy() string {
+	switch policy := os.Getenv("EMAIL_VERIFICATION_POLICY"); policy {
+	case VerificationPolicyOrders, VerificationPolicyLogin:
+		return policy
+	default:
+		return VerificationPolicyNone
+	}
+}
+
+// tokenSecret is the key used to sign single-use tokens sent by email, set by LoadSigningKeys
+var tokenSecret []byte
+
+// TokenSecret returns the key used to sign single-use tokens sent by email
+func TokenSecret() []byte {
+	return tokenSecret
+}
+
+// LoginLockoutSettings controls how failed logins lock out an account or client IP
+type LoginLockoutSettings struct {
+	// MaxAttempts is the number of failures per account before it is locked
+	MaxAttempts int
+	// MaxAttemptsPerIP is the number of failures per client IP before it is locked
+	MaxAttemptsPerIP int
+	// BaseLockout is the first lockout duration; it doubles with each further failure
+	BaseLockout time.Duration
+	// MaxLockout caps the lockout duration
+	MaxLockout time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+}
+
+// LoginLockout returns the lockout settings from LOGIN_MAX_ATTEMPTS, LOGIN_MAX_ATTEMPTS_PER_IP,
+// LOGIN_LOCKOUT_BASE, LOGIN_LOCKOUT_MAX and LOGIN_ATTEMPT_WINDOW
+func LoginLockout() LoginLockoutSettings {
+	return LoginLockoutSettings{
+		MaxAttempts:      envInt("LOGIN_MAX_ATTEMPTS", 5),
+		MaxAttemptsPerIP: envInt("LOGIN_MAX_ATTEMPTS_PER_IP", 20),
+		BaseLockout:      envDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		MaxLockout:       envDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+		Window:           envDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+	}
+}
+
+func envInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func envDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// TOTPIssuer is the account issuer shown in authenticator apps
+func TOTPIssuer() string {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "Go Fiber API"
+	}
+	return issuer
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"go-fiber-api/money"
+	"go-fiber-api/tax"
+	"os"
+	"strings"
+)
+
+// Currency returns the ISO 4217 code prices are kept in, from CURRENCY (default USD)
+func Currency() string {
+	currency := strings.ToUpper(os.Getenv("CURRENCY"))
+	if !money.ValidCurrency(currency) {
+		return "USD"
+	}
+	return currency
+}
+
+// TaxMode returns whether catalog prices exclude or include tax, from TAX_MODE (default exclusive)
+func TaxMode() string {
+	mode := strings.ToLower(os.Getenv("TAX_MODE"))
+	if !tax.ValidMode(mode) {
+		return tax.ModeExclusive
+	}
+	return mode
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"go-fiber-api/models"
+	"go-fiber-api/repository"
 	"log"
 	"os"
 
@@ -11,6 +12,16 @@ import (
 
 var DB *gorm.DB
 
+// Repositories for users, products, orders and identities, and the transaction runner. Tests can
+// replace these with fakes; the remaining tables are queried through DB and cannot be faked.
+var (
+	Users      repository.UserRepository
+	Products   repository.ProductRepository
+	Orders     repository.OrderRepository
+	Identities repository.IdentityRepository
+	Tx         repository.TxRunner
+)
+
 func ConnectDatabase() {
 	var err error
 
@@ -25,11 +36,29 @@ func ConnectDatabase() {
 		log.Fatal("Failed to connect to database: ", err)
 	}
 
+	// Columns whose existing rows need backfilling must be detected before they are added
+	addingEmailVerification := !DB.Migrator().HasColumn(&models.User{}, "email_verified_at")
+
 	// Auto migrate the schema
-	err = DB.AutoMigrate(&models.User{}, &models.Product{}, &models.Category{}, &models.Order{})
+	err = DB.AutoMigrate(&models.User{}, &models.Product{}, &models.Category{}, &models.Order{},
+		&models.OrderItem{}, &models.Review{}, &models.WishlistItem{},
+		&models.ProductOption{}, &models.ProductOptionValue{}, &models.ProductVariant{},
+		&models.ProductImage{}, &models.UserToken{}, &models.LoginThrottle{},
+		&models.RecoveryCode{}, &models.APIKey{}, &models.UserIdentity{}, &models.OIDCLoginState{},
+		&models.Session{}, &models.ExchangeRate{}, &models.TaxRule{}, &models.OrderTaxLine{},
+		&models.UserAddress{}, &models.ShippingMethod{}, &models.Shipment{}, &models.ShipmentItem{},
+		&models.OrderReturn{}, &models.OrderReturnItem{}, &models.Refund{})
 	if err != nil {
 		log.Fatal("Failed to migrate database: ", err)
 	}
 
+	migrateData(addingEmailVerification)
+
+	Users = repository.NewGormUserRepository(DB)
+	Products = repository.NewGormProductRepository(DB)
+	Orders = repository.NewGormOrderRepository(DB)
+	Identities = repository.NewGormIdentityRepository(DB)
+	Tx = repository.NewGormTxRunner(DB)
+
 	log.Println("Database connected successfully")
 }
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"go-fiber-api/tokens"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Keys signs and verifies access tokens
+var Keys *tokens.KeySet
+
+// LoadSigningKeys loads the active signing key from JWT_SIGNING_KEY_FILE and any keys that are
+// still accepted during a rotation from the comma-separated JWT_VERIFICATION_KEY_FILES. It also
+// sets the secret that signs emailed tokens.
+func LoadSigningKeys() {
+	var signing *tokens.Key
+	var err error
+	if path := os.Getenv("JWT_SIGNING_KEY_FILE"); path != "" {
+		signing, err = tokens.LoadKeyFile(path)
+		if err != nil {
+			log.Fatal("Failed to load JWT signing key: ", err)
+		}
+	} else {
+		// Development fallback: tokens stop verifying whenever the server restarts
+		log.Println("WARNING: JWT_SIGNING_KEY_FILE is not set, signing tokens with an ephemeral Ed25519 key")
+		signing, err = tokens.GenerateEd25519Key()
+		if err != nil {
+			log.Fatal("Failed to generate JWT signing key: ", err)
+		}
+	}
+
+	var verification []*tokens.Key
+	for _, path := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		key, err := tokens.LoadKeyFile(path)
+		if err != nil {
+			log.Fatal("Failed to load JWT verification key: ", err)
+		}
+		verification = append(verification, key)
+	}
+
+	Keys, err = tokens.NewKeySet(signing, verification...)
+	if err != nil {
+		log.Fatal("Failed to initialize JWT keys: ", err)
+	}
+
+	log.Printf("JWT signing key %s (%s) loaded with %d additional verification keys", signing.ID, signing.Method.Alg(), len(verification))
+
+	// Emailed tokens are signed with TOKEN_SECRET, or a secret derived from the signing key
+	if secret := os.Getenv("TOKEN_SECRET"); secret != "" {
+		tokenSecret = []byte(secret)
+	} else if tokenSecret, err = signing.DeriveSecret("email-tokens"); err != nil {
+		log.Fatal("Failed to derive the email token secret: ", err)
+	}
+}
+
+// JWTIssuer is the iss claim of issued tokens, set with JWT_ISSUER
+func JWTIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "go-fiber-api"
+}
+
+// JWTAudience is the aud claim of issued tokens, set with JWT_AUDIENCE
+func JWTAudience() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
+	}
+	return "go-fiber-api"
+}
+
+// AccessTokenTTL is how long access tokens are valid, set with JWT_ACCESS_TOKEN_TTL
+func AccessTokenTTL() time.Duration {
+	return envDuration("JWT_ACCESS_TOKEN_TTL", 72*time.Hour)
+}
+
+// TokenValidation returns the checks applied to tokens issued for purpose; access tokens have
+// an empty purpose. JWT_CLOCK_SKEW sets the tolerated clock difference between servers.
+func TokenValidation(purpose string) tokens.Validation {
+	return tokens.Validation{
+		Issuer:   JWTIssuer(),
+		Audience: JWTAudience(),
+		Purpose:  purpose,
+		Leeway:   envDuration("JWT_CLOCK_SKEW", 30*time.Second),
+	}
+}
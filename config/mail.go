@@ -0,0 +1,59 @@
+package config
+
+import (
+	"go-fiber-api/mailer"
+	"log"
+	"os"
+	"strconv"
+)
+
+// Mailer sends transactional email such as verification links
+var Mailer mailer.Mailer
+
+func ConnectMailer() {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "", "log":
+		// Development default: messages are written to MAIL_LOG_FILE, or to the log when unset
+		Mailer = &mailer.LogMailer{Path: os.Getenv("MAIL_LOG_FILE"), From: from}
+	case "smtp":
+		port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		if err != nil {
+			port = 587
+		}
+		Mailer = &mailer.SMTPMailer{
+			Host:               os.Getenv("SMTP_HOST"),
+			Port:               port,
+			Username:           os.Getenv("SMTP_USERNAME"),
+			Password:           os.Getenv("SMTP_PASSWORD"),
+			From:               from,
+			InsecureSkipVerify: os.Getenv("SMTP_INSECURE_SKIP_VERIFY") == "true",
+		}
+	default:
+		log.Fatalf("Unknown MAIL_DRIVER %q", driver)
+	}
+
+	log.Println("Mailer initialized successfully")
+}
+
+// AppBaseURL is the public URL of the API, used to build links in emails
+func AppBaseURL() string {
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:3000"
+	}
+	return baseURL
+}
+
+// PasswordResetURL is the page password reset links point to; the token is appended as a query parameter
+func PasswordResetURL() string {
+	resetURL := os.Getenv("PASSWORD_RESET_URL")
+	if resetURL == "" {
+		resetURL = AppBaseURL() + "/reset-password"
+	}
+	return resetURL
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"go-fiber-api/models"
+	"go-fiber-api/money"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// migrateData backfills data for columns that AutoMigrate adds to existing tables.
+// addingEmailVerification reports whether users.email_verified_at was just added.
+func migrateData(addingEmailVerification bool) {
+	// Categories created before slugs existed get one derived from their name
+	var categories []models.Category
+	DB.Where("slug IS NULL OR slug = ''").Find(&categories)
+	for i := range categories {
+		if err := DB.Save(&categories[i]).Error; err != nil {
+			log.Printf("Failed to backfill slug for category %d: %v", categories[i].ID, err)
+		}
+	}
+
+	// Users who registered before email verification existed keep the access they had. This
+	// runs only when the column is added, so later unverified registrations stay unverified.
+	if addingEmailVerification {
+		if err := DB.Exec("UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL").Error; err != nil {
+			log.Printf("Failed to backfill email verification: %v", err)
+		}
+	}
+
+	// Addresses that differ only in case reach the same mailbox, so they are unique regardless of case
+	if err := DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email))").Error; err != nil {
+		log.Printf("Failed to create case-insensitive email index, check for addresses that differ only in case: %v", err)
+	}
+
+	migrateMoneyColumns()
+
+	// Orders placed before taxes were calculated had no tax: their subtotal is their total
+	if err := DB.Exec("UPDATE orders SET subtotal_amount = total_amount, subtotal_currency = total_currency, " +
+		"tax_total_amount = 0, tax_total_currency = total_currency WHERE subtotal_currency = '' AND total_currency <> ''").Error; err != nil {
+		log.Printf("Failed to backfill order subtotals: %v", err)
+	}
+}
+
+// floatMoneyColumns were float columns of major units before amounts became money.Money,
+// which is stored as <column>_amount in minor units and <column>_currency
+var floatMoneyColumns = []struct{ table, column string }{
+	{"products", "price"},
+	{"orders", "total"},
+	{"order_items", "price"},
+	{"wishlist_items", "price_when_added"},
+	{"product_variants", "price_override"},
+}
+
+// migrateMoneyColumns converts remaining float columns to minor units of the store currency
+// and drops them. Each column is converted in its own transaction, so a failed run resumes.
+func migrateMoneyColumns() {
+	currency := Currency()
+	scale := "1" + strings.Repeat("0", money.Exponent(currency))
+
+	for _, c := range floatMoneyColumns {
+		if !DB.Migrator().HasColumn(c.table, c.column) {
+			continue
+		}
+
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			// NULL overrides stay unset, which is the zero amount without a currency
+			convert := fmt.Sprintf("UPDATE %[1]s SET %[2]s_amount = ROUND(CAST(%[2]s AS numeric) * %[3]s), %[2]s_currency = ? WHERE %[2]s IS NOT NULL",
+				c.table, c.column, scale)
+			if err := tx.Exec(convert, currency).Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(c.table, c.column)
+		})
+		if err != nil {
+			log.Printf("Failed to convert %s.%s to money: %v", c.table, c.column, err)
+			continue
+		}
+		log.Printf("Converted %s.%s to %s minor units", c.table, c.column, currency)
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"go-fiber-api/oidc"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// OIDCProviders holds the configured external identity providers by name
+var OIDCProviders = map[string]*oidc.Provider{}
+
+// LoadOIDCProviders reads the providers named in the comma-separated OIDC_PROVIDERS, each
+// configured with OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET and
+// optionally OIDC_<NAME>_SCOPES (space-separated, default "openid email profile").
+func LoadOIDCProviders() {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := &oidc.Provider{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+			RedirectURL:  APIBaseURL() + "/auth/oidc/" + name + "/callback",
+			HTTPClient:   client,
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			log.Fatalf("%sISSUER and %sCLIENT_ID are required for OIDC provider %q", prefix, prefix, name)
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+
+		OIDCProviders[name] = provider
+		log.Printf("OIDC provider %s configured (issuer %s)", name, provider.Issuer)
+	}
+}
+
+// APIBaseURL is the public URL of this API, used in redirect URIs registered with identity providers
+func APIBaseURL() string {
+	if baseURL := os.Getenv("API_BASE_URL"); baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
+	return "http://localhost:3000"
+}
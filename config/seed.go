@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"go-fiber-api/models"
+	"go-fiber-api/money"
 	"log"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -34,14 +38,14 @@ func SeedTestData() {
 		{
 			Name:        "Test Laptop",
 			Description: "A test laptop for API testing",
-			Price:       999.99,
+			Price:       money.MustParse("999.99", Currency()),
 			Stock:       10,
 			CategoryID:  electronics.ID,
 		},
 		{
 			Name:        "Test Phone",
 			Description: "A test phone for API testing",
-			Price:       699.99,
+			Price:       money.MustParse("699.99", Currency()),
 			Stock:       25,
 			CategoryID:  electronics.ID,
 		},
@@ -55,17 +59,21 @@ func SeedTestData() {
 		}
 	}
 
+	seedClothingVariants(db)
+
 	// Create test user for authentication testing - ensure fresh password
 	var testUser models.User
 	// Check for both active and soft-deleted users
 	result := db.Unscoped().Where("email = ?", "dredd.test@example.com").First(&testUser)
 	if result.Error == gorm.ErrRecordNotFound {
 		// Create new test user
+		verifiedAt := time.Now()
 		testUser = models.User{
-			Email:     "dredd.test@example.com",
-			FirstName: "Test",
-			LastName:  "User",
-			Role:      "user",
+			Email:           "dredd.test@example.com",
+			FirstName:       "Test",
+			LastName:        "User",
+			Role:            "user",
+			EmailVerifiedAt: &verifiedAt,
 		}
 
 		// Use bcrypt to hash the password like in auth controller
@@ -90,6 +98,10 @@ func SeedTestData() {
 		}
 		testUser.Password = string(hashedPassword)
 		testUser.DeletedAt = gorm.DeletedAt{} // Restore if soft-deleted
+		if testUser.EmailVerifiedAt == nil {
+			verifiedAt := time.Now()
+			testUser.EmailVerifiedAt = &verifiedAt
+		}
 
 		if err := db.Unscoped().Save(&testUser).Error; err != nil {
 			log.Printf("Failed to update test user password: %v", err)
@@ -102,19 +114,19 @@ func SeedTestData() {
 	testOrders := []models.Order{
 		{
 			UserID: testUser.ID,
-			Total:  99.99,
+			Total:  money.MustParse("99.99", Currency()),
 			Status: "pending",
 		},
 		{
 			UserID: testUser.ID,
-			Total:  149.99,
+			Total:  money.MustParse("149.99", Currency()),
 			Status: "pending",
 		},
 	}
 
 	for _, order := range testOrders {
 		var existingOrder models.Order
-		result := db.Where("user_id = ? AND total = ?", order.UserID, order.Total).First(&existingOrder)
+		result := db.Where("user_id = ? AND total_amount = ?", order.UserID, order.Total.Amount).First(&existingOrder)
 		if result.Error == gorm.ErrRecordNotFound {
 			if err := db.Create(&order).Error; err != nil {
 				log.Printf("Failed to create test order: %v", err)
@@ -126,3 +138,62 @@ func SeedTestData() {
 
 	log.Println("Test data seeded successfully")
 }
+
+// seedClothingVariants creates a clothing product sold in several sizes and colors,
+// with one variant per combination
+func seedClothingVariants(db *gorm.DB) {
+	var existingProduct models.Product
+	if db.Where("name = ?", "Test T-Shirt").First(&existingProduct).Error != gorm.ErrRecordNotFound {
+		return
+	}
+
+	var clothing models.Category
+	db.Where("name = ?", "Clothing").First(&clothing)
+
+	sizes := []string{"S", "M", "L"}
+	colors := []string{"Black", "White"}
+	stockPerVariant := 5
+
+	product := models.Product{
+		Name:        "Test T-Shirt",
+		Description: "A test t-shirt available in several sizes and colors",
+		Price:       money.MustParse("19.99", Currency()),
+		Stock:       len(sizes) * len(colors) * stockPerVariant,
+		CategoryID:  clothing.ID,
+		Options: []models.ProductOption{
+			{Name: "Size", Position: 1, Values: optionValues(sizes)},
+			{Name: "Color", Position: 2, Values: optionValues(colors)},
+		},
+	}
+	if err := db.Create(&product).Error; err != nil {
+		log.Printf("Failed to create test variant product: %v", err)
+		return
+	}
+
+	// Large sizes cost a little more than the base price
+	largePrice := money.MustParse("21.99", Currency())
+	for _, size := range product.Options[0].Values {
+		for _, color := range product.Options[1].Values {
+			variant := models.ProductVariant{
+				ProductID:    product.ID,
+				SKU:          fmt.Sprintf("TSHIRT-%s-%s", strings.ToUpper(color.Value[:3]), size.Value),
+				Stock:        stockPerVariant,
+				OptionValues: []models.ProductOptionValue{size, color},
+			}
+			if size.Value == "L" {
+				variant.PriceOverride = largePrice
+			}
+			if err := db.Create(&variant).Error; err != nil {
+				log.Printf("Failed to create test variant %s: %v", variant.SKU, err)
+			}
+		}
+	}
+}
+
+func optionValues(values []string) []models.ProductOptionValue {
+	result := make([]models.ProductOptionValue, 0, len(values))
+	for i, value := range values {
+		result = append(result, models.ProductOptionValue{Value: value, Position: i + 1})
+	}
+	return result
+}
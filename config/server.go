@@ -0,0 +1,19 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// ProxySettings returns the header the client IP is read from and the proxies trusted to set
+// it, from PROXY_HEADER and the comma-separated TRUSTED_PROXIES (IPs or CIDR ranges). The
+// header is ignored on requests that do not come from a trusted proxy, so without
+// TRUSTED_PROXIES the client IP is always the peer address.
+func ProxySettings() (header string, trusted []string) {
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trusted = append(trusted, proxy)
+		}
+	}
+	return os.Getenv("PROXY_HEADER"), trusted
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"go-fiber-api/storage"
+	"log"
+	"os"
+	"strconv"
+)
+
+// Storage holds uploaded files such as product images
+var Storage storage.Storage
+
+// MaxImageSize is the largest accepted image upload in bytes
+var MaxImageSize int64 = 5 << 20
+
+// MaxImagePixels is the largest accepted image area (width × height) in pixels
+var MaxImagePixels int64 = 40_000_000
+
+// ThumbnailSize is the maximum width and height of generated thumbnails in pixels
+var ThumbnailSize = 300
+
+func ConnectStorage() {
+	if size, err := strconv.ParseInt(os.Getenv("IMAGE_MAX_SIZE"), 10, 64); err == nil && size > 0 {
+		MaxImageSize = size
+	}
+	if pixels, err := strconv.ParseInt(os.Getenv("IMAGE_MAX_PIXELS"), 10, 64); err == nil && pixels > 0 {
+		MaxImagePixels = pixels
+	}
+	if size, err := strconv.Atoi(os.Getenv("IMAGE_THUMBNAIL_SIZE")); err == nil && size > 0 {
+		ThumbnailSize = size
+	}
+
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		root := os.Getenv("STORAGE_LOCAL_DIR")
+		if root == "" {
+			root = "uploads"
+		}
+		baseURL := os.Getenv("STORAGE_LOCAL_URL")
+		if baseURL == "" {
+			baseURL = "/uploads"
+		}
+
+		local, err := storage.NewLocalStorage(root, baseURL)
+		if err != nil {
+			log.Fatal("Failed to initialize local storage: ", err)
+		}
+		Storage = local
+	case "s3":
+		// Works with any S3-compatible service; for MinIO set STORAGE_S3_ENDPOINT=http://localhost:9000
+		s3 := &storage.S3Storage{
+			Endpoint:        os.Getenv("STORAGE_S3_ENDPOINT"),
+			Region:          os.Getenv("STORAGE_S3_REGION"),
+			Bucket:          os.Getenv("STORAGE_S3_BUCKET"),
+			AccessKeyID:     os.Getenv("STORAGE_S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("STORAGE_S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("STORAGE_S3_PUBLIC_URL"),
+		}
+		if s3.Region == "" {
+			s3.Region = "us-east-1"
+		}
+		if s3.Endpoint == "" || s3.Bucket == "" {
+			log.Fatal("STORAGE_S3_ENDPOINT and STORAGE_S3_BUCKET are required for the s3 storage driver")
+		}
+		Storage = s3
+	default:
+		log.Fatalf("Unknown STORAGE_DRIVER %q", driver)
+	}
+
+	log.Println("Storage initialized successfully")
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-api/config"
+	"go-fiber-api/mailer"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const emailChangeTTL = 24 * time.Hour
+
+// ChangePasswordRequest struct for changing the password of the authenticated user
+// @Description Change password request payload
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"password123"`
+	NewPassword     string `json:"new_password" validate:"required,min=6" example:"newpassword123"`
+}
+
+// ChangeEmailRequest struct for changing the email address of the authenticated user
+// @Description Change email request payload
+type ChangeEmailRequest struct {
+	Email           string `json:"email" validate:"required,email" example:"new@example.com"`
+	CurrentPassword string `json:"current_password" validate:"required" example:"password123"`
+}
+
+// ChangePassword - Protected endpoint to change the user's password
+// @Summary      Change password
+// @Description  Change the authenticated user's password. Other sessions are signed out, pending email changes and password reset links stop working, and a fresh token is returned for the current one
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        request body ChangePasswordRequest true "Current and new password"
+// @Success      200  {object}  models.TokenResponse  "Password changed, new token"
+// @Failure      400  {object}  models.Problem  "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem  "Unauthorized or wrong current password"
+// @Failure      404  {object}  models.Problem  "User not found"
+// @Failure      500  {object}  models.Problem  "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/password [put]
+func ChangePassword(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	user, err := config.Users.FindByID(userID)
+	if err != nil {
+		return problem.NotFound("user_not_found", "User not found")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return problem.Unauthorized("current_password_incorrect", "Current password is incorrect")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return problem.Internal("Could not hash password")
+	}
+
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		if err := config.Users.WithTx(tx).Update(user.ID, map[string]interface{}{"password": string(hashedPassword)}); err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, user.ID, 0); err != nil {
+			return err
+		}
+		// Pending email changes and reset links were requested under the old password
+		return revokeUserTokens(tx, user.ID, models.TokenPurposeEmailChange, models.TokenPurposePasswordReset)
+	})
+	if err != nil {
+		return problem.Internal("Could not change password")
+	}
+
+	tokenString, err := generateToken(c, *user)
+	if err != nil {
+		return problem.Internal("Could not generate token")
+	}
+
+	return c.JSON(models.TokenResponse{Token: tokenString})
+}
+
+// ChangeEmail - Protected endpoint to request a change of the user's email address
+// @Summary      Change email
+// @Description  Request a change of the authenticated user's email address. A verification link is sent to the new address and the change takes effect once it is opened
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        request body ChangeEmailRequest true "New email and current password"
+// @Success      202  {object}  models.MessageResponse "Verification link sent to the new address"
+// @Failure      400  {object}  models.Problem   "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem   "Unauthorized or wrong current password"
+// @Failure      404  {object}  models.Problem   "User not found"
+// @Failure      409  {object}  models.Problem   "Email already in use"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/email [put]
+func ChangeEmail(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req ChangeEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	user, err := config.Users.FindByID(userID)
+	if err != nil {
+		return problem.NotFound("user_not_found", "User not found")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return problem.Unauthorized("current_password_incorrect", "Current password is incorrect")
+	}
+
+	if strings.EqualFold(req.Email, user.Email) {
+		return problem.BadRequest("email_unchanged", "New email must differ from the current one")
+	}
+
+	inUse, err := config.Users.EmailInUse(req.Email)
+	if err != nil {
+		return problem.Internal("Could not request email change")
+	}
+	if inUse {
+		return problem.Conflict("email_taken", "User with this email already exists")
+	}
+
+	token, err := issueUserToken(config.DB, user.ID, models.TokenPurposeEmailChange, req.Email, emailChangeTTL)
+	if err != nil {
+		return problem.Internal("Could not request email change")
+	}
+
+	link := config.AppBaseURL() + "/auth/verify?token=" + url.QueryEscape(token)
+	err = config.Mailer.Send(c.Context(), mailer.Message{
+		To:      req.Email,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease confirm that you want to use this address for your account by opening the link below:\n\n%s\n\nThe link expires in %d hours.\n",
+			user.FirstName, link, int(emailChangeTTL.Hours())),
+	})
+	if err != nil {
+		log.Printf("Failed to send email change confirmation to user %d: %v", user.ID, err)
+		return problem.Internal("Could not send confirmation email")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.MessageResponse{
+		Message: "A confirmation link has been sent to the new email address",
+	})
+}
+
+// applyEmailChange switches the user to the new address stored with a confirmed email change token
+func applyEmailChange(tx *gorm.DB, token *models.UserToken) error {
+	users := config.Users.WithTx(tx)
+	inUse, err := users.EmailInUse(token.Data)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errEmailInUse
+	}
+	return users.Update(token.UserID, map[string]interface{}{
+		"email":             token.Data,
+		"email_verified_at": time.Now(),
+	})
+}
+
+var errEmailInUse = errors.New("email already in use")
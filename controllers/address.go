@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"errors"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetAddresses - Protected endpoint to list the user's address book
+// @Summary      List addresses
+// @Description  List the authenticated user's saved addresses, default addresses first
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   models.UserAddress   "Addresses"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/addresses [get]
+func GetAddresses(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	addresses := []models.UserAddress{}
+	if err := config.DB.Where("user_id = ?", userID).
+		Order("default_shipping DESC, default_billing DESC, created_at DESC").
+		Find(&addresses).Error; err != nil {
+		return problem.Internal("Failed to fetch addresses")
+	}
+	return c.JSON(addresses)
+}
+
+// CreateAddress - Protected endpoint to add an address to the address book
+// @Summary      Add address
+// @Description  Save an address to the authenticated user's address book. The first address becomes the default shipping and billing address; marking another one as default replaces the previous default
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        request body models.AddressRequest true "Address"
+// @Success      201  {object}  models.UserAddress   "Saved address"
+// @Failure      400  {object}  models.Problem "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/addresses [post]
+func CreateAddress(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.AddressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	req.Normalize()
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	address := models.UserAddress{UserID: userID}
+	req.Apply(&address)
+
+	var existing int64
+	config.DB.Model(&models.UserAddress{}).Where("user_id = ?", userID).Count(&existing)
+	if existing == 0 {
+		address.DefaultShipping = true
+		address.DefaultBilling = true
+	}
+
+	if err := saveAddress(&address); err != nil {
+		return problem.Internal("Failed to save address")
+	}
+	return c.Status(fiber.StatusCreated).JSON(address)
+}
+
+// UpdateAddress - Protected endpoint to change an address book entry
+// @Summary      Update address
+// @Description  Change one of the authenticated user's saved addresses. Orders keep the address they were placed with
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                    true  "Address ID"
+// @Param        request body  models.AddressRequest  true  "Address"
+// @Success      200  {object}  models.UserAddress   "Updated address"
+// @Failure      400  {object}  models.Problem "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      404  {object}  models.Problem "Address not found"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/addresses/{id} [put]
+func UpdateAddress(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	addressID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid address ID")
+	}
+
+	var address models.UserAddress
+	if err := config.DB.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+		return problem.NotFound("address_not_found", "Address not found")
+	}
+
+	var req models.AddressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	req.Normalize()
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	req.Apply(&address)
+	if err := saveAddress(&address); err != nil {
+		return problem.Internal("Failed to save address")
+	}
+	return c.JSON(address)
+}
+
+// DeleteAddress - Protected endpoint to remove an address book entry
+// @Summary      Delete address
+// @Description  Remove one of the authenticated user's saved addresses. When it was a default address, the most recently added remaining address takes its place
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Address ID"
+// @Success      200  {object}  models.MessageResponse "Address deleted"
+// @Failure      400  {object}  models.Problem   "Invalid address ID"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      404  {object}  models.Problem   "Address not found"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/addresses/{id} [delete]
+func DeleteAddress(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	addressID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid address ID")
+	}
+
+	var address models.UserAddress
+	if err := config.DB.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+		return problem.NotFound("address_not_found", "Address not found")
+	}
+
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.DefaultShipping && !address.DefaultBilling {
+			return nil
+		}
+
+		var successor models.UserAddress
+		err := tx.Where("user_id = ?", userID).Order("created_at DESC").First(&successor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		successor.DefaultShipping = successor.DefaultShipping || address.DefaultShipping
+		successor.DefaultBilling = successor.DefaultBilling || address.DefaultBilling
+		return tx.Save(&successor).Error
+	})
+	if err != nil {
+		return problem.Internal("Failed to delete address")
+	}
+
+	return c.JSON(models.MessageResponse{Message: "Address deleted successfully"})
+}
+
+// saveAddress stores the address and takes the default flags it holds away from the user's
+// other addresses, so there is at most one default of each kind
+func saveAddress(address *models.UserAddress) error {
+	return config.Tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(address).Error; err != nil {
+			return err
+		}
+
+		others := tx.Model(&models.UserAddress{}).Where("user_id = ? AND id <> ?", address.UserID, address.ID)
+		if address.DefaultShipping {
+			if err := others.Session(&gorm.Session{}).Update("default_shipping", false).Error; err != nil {
+				return err
+			}
+		}
+		if address.DefaultBilling {
+			if err := others.Session(&gorm.Session{}).Update("default_billing", false).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// orderAddress returns the user's address book entry with the given ID, or the default one
+// picked by column when id is nil. A missing default is not an error: the address is empty.
+func orderAddress(userID uint, id *uint, defaultColumn string) (models.Address, *problem.Error) {
+	query := config.DB.Where("user_id = ?", userID)
+	if id != nil {
+		query = query.Where("id = ?", *id)
+	} else {
+		query = query.Where(defaultColumn+" = ?", true)
+	}
+
+	var address models.UserAddress
+	err := query.First(&address).Error
+	switch {
+	case err == nil:
+		return address.Address, nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return models.Address{}, problem.Internal("Failed to fetch addresses")
+	case id != nil:
+		return models.Address{}, problem.BadRequest("address_not_found", "Address not found in your address book")
+	default:
+		return models.Address{}, nil
+	}
+}
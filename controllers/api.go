@@ -1,50 +1,83 @@
 package controllers
 
 import (
+	"errors"
 	"go-fiber-api/config"
 	"go-fiber-api/models"
+	"go-fiber-api/money"
+	"go-fiber-api/problem"
+	"go-fiber-api/repository"
+	"go-fiber-api/tax"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// productSortOrders maps the supported sort keys of the product list to ORDER BY clauses
+var productSortOrders = map[string]string{
+	"price":   "price_amount ASC, id ASC",
+	"-price":  "price_amount DESC, id ASC",
+	"rating":  "average_rating ASC, review_count ASC, id ASC",
+	"-rating": "average_rating DESC, review_count DESC, id ASC",
+	"reviews": "review_count DESC, id ASC",
+	"newest":  "created_at DESC, id DESC",
+}
+
 // GetProducts - Public endpoint to get all products
 // @Summary      Get all products
 // @Description  Retrieve a list of all products with their categories
 // @Tags         Products
 // @Accept       json
 // @Produce      json
+// @Param        sort     query string false "Sort order (price, -price, rating, -rating, reviews, newest)"
+// @Param        currency query string false "Currency to show prices in; defaults to the Accept-Currency header, then the store currency"
 // @Param        simulate query string false "Simulate error (500 for server error)"
 // @Success      200  {array}   models.Product "List of products"
-// @Failure      500  {object}  models.ErrorResponse      "Internal server error"
+// @Failure      400  {object}  models.Problem      "Invalid sort key or unsupported currency"
+// @Failure      500  {object}  models.Problem      "Internal server error"
 // @Router       /api/products [get]
 func GetProducts(c *fiber.Ctx) error {
 	// Support simulating server error for testing
 	if c.Query("simulate") == "500" {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Simulated server error for testing",
-		})
+		return problem.Internal("Simulated server error for testing")
 	}
 
-	var products []models.Product
-	if err := config.DB.Preload("Category").Find(&products).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to fetch products",
-		})
+	var filter repository.ProductFilter
+	if sort := c.Query("sort"); sort != "" {
+		order, ok := productSortOrders[sort]
+		if !ok {
+			return problem.BadRequest("invalid_sort", "Invalid sort key")
+		}
+		filter.Order = order
+	}
+
+	rate, err := displayRate(c)
+	if err != nil {
+		return err
+	}
+
+	products, err := config.Products.List(filter)
+	if err != nil {
+		return problem.Internal("Failed to fetch products")
+	}
+	for i := range products {
+		products[i].ConvertPrices(rate)
 	}
 	return c.JSON(products)
 }
 
 // GetProduct - Public endpoint to get product by ID
 // @Summary      Get product by ID
-// @Description  Retrieve a specific product by its ID
+// @Description  Retrieve a specific product by its ID, including its options and variants
 // @Tags         Products
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Product ID"
+// @Param        currency query string false "Currency to show prices in; defaults to the Accept-Currency header, then the store currency"
 // @Success      200  {object}  models.Product "Product details"
-// @Failure      400  {object}  models.ErrorResponse      "Invalid product ID"
-// @Failure      404  {object}  models.ErrorResponse      "Product not found"
+// @Failure      400  {object}  models.Problem      "Invalid product ID or unsupported currency"
+// @Failure      404  {object}  models.Problem      "Product not found"
 // @Router       /api/products/{id} [get]
 func GetProduct(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -52,37 +85,41 @@ func GetProduct(c *fiber.Ctx) error {
 	// Convert id to integer to check if it's valid
 	productID, err := strconv.Atoi(id)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "Invalid product ID",
-		})
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid product ID")
 	}
 
-	var product models.Product
-	if err := config.DB.Preload("Category").Where("id = ?", productID).First(&product).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
-			Error: "Product not found",
-		})
+	rate, err := displayRate(c)
+	if err != nil {
+		return err
+	}
+
+	product, err := config.Products.FindDetails(uint(productID))
+	if err != nil {
+		return problem.NotFound("product_not_found", "Product not found")
 	}
+
+	for i := range product.Variants {
+		product.Variants[i].Price = product.Variants[i].EffectivePrice(product.Price)
+	}
+	product.ConvertPrices(rate)
 	return c.JSON(product)
 }
 
 // GetCategories - Public endpoint to get all categories
 // @Summary      Get all categories
-// @Description  Retrieve a list of all product categories
+// @Description  Retrieve the category tree; each root category lists its subcategories under children
 // @Tags         Categories
 // @Accept       json
 // @Produce      json
-// @Success      200  {array}   models.Category "List of categories"
-// @Failure      500  {object}  models.ErrorResponse       "Internal server error"
+// @Success      200  {array}   models.Category "Category tree"
+// @Failure      500  {object}  models.Problem       "Internal server error"
 // @Router       /api/categories [get]
 func GetCategories(c *fiber.Ctx) error {
 	var categories []models.Category
-	if err := config.DB.Find(&categories).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to fetch categories",
-		})
+	if err := config.DB.Order("name").Find(&categories).Error; err != nil {
+		return problem.Internal("Failed to fetch categories")
 	}
-	return c.JSON(categories)
+	return c.JSON(models.BuildCategoryTree(categories))
 }
 
 // GetProfile - Protected endpoint to get user profile
@@ -92,28 +129,23 @@ func GetCategories(c *fiber.Ctx) error {
 // @Accept       json
 // @Produce      json
 // @Param        simulate query string false "Simulate error (404 for not found)"
-// @Success      200  {object}  models.User "User profile"
-// @Failure      401  {object}  models.ErrorResponse   "Unauthorized"
-// @Failure      404  {object}  models.ErrorResponse   "User not found"
+// @Success      200  {object}  models.UserResponse "User profile"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      404  {object}  models.Problem   "User not found"
 // @Security     Bearer
 // @Router       /api/profile [get]
 func GetProfile(c *fiber.Ctx) error {
 	// Support simulating 404 error for testing
 	if c.Query("simulate") == "404" {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
-			Error: "User profile not found for testing",
-		})
+		return problem.NotFound("user_not_found", "User profile not found for testing")
 	}
 
 	userID := c.Locals("userID").(uint)
-	var user models.User
-
-	if err := config.DB.First(&user, userID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
-			Error: "User not found",
-		})
+	user, err := config.Users.FindByID(userID)
+	if err != nil {
+		return problem.NotFound("user_not_found", "User not found")
 	}
-	return c.JSON(user)
+	return c.JSON(models.NewUserResponse(*user))
 }
 
 // UpdateProfile - Protected endpoint to update user profile
@@ -122,129 +154,327 @@ func GetProfile(c *fiber.Ctx) error {
 // @Tags         Profile
 // @Accept       json
 // @Produce      json
-// @Param        request body models.User true "Updated profile data"
-// @Success      200  {object}  models.User "Updated user profile"
-// @Failure      400  {object}  models.ErrorResponse   "Invalid input"
-// @Failure      401  {object}  models.ErrorResponse   "Unauthorized"
-// @Failure      404  {object}  models.ErrorResponse   "User not found"
-// @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Param        request body models.UpdateProfileRequest true "Updated profile data"
+// @Success      200  {object}  models.UserResponse "Updated user profile"
+// @Failure      400  {object}  models.Problem   "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      404  {object}  models.Problem   "User not found"
+// @Failure      500  {object}  models.Problem   "Internal server error"
 // @Security     Bearer
 // @Router       /api/profile [put]
 func UpdateProfile(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	var user models.User
-
-	if err := config.DB.First(&user, userID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
-			Error: "User not found",
-		})
+	user, err := config.Users.FindByID(userID)
+	if err != nil {
+		return problem.NotFound("user_not_found", "User not found")
 	}
 
-	var updateData models.User
-	if err := c.BodyParser(&updateData); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "Invalid input",
-		})
+	var req models.UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
 	}
 
-	// Validate required fields for 400 error test
-	if updateData.FirstName == "" || updateData.LastName == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "First name and last name are required",
-		})
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
 	}
 
-	user.FirstName = updateData.FirstName
-	user.LastName = updateData.LastName
+	req.Apply(user)
 
-	if err := config.DB.Save(&user).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to update profile",
-		})
+	if err := config.Users.Save(user); err != nil {
+		return problem.Internal("Failed to update profile")
 	}
 
-	return c.JSON(user)
+	return c.JSON(models.NewUserResponse(*user))
 }
 
 // CreateOrder - Protected endpoint to create new order
 // @Summary      Create new order
-// @Description  Create a new order for the authenticated user
+// @Description  Create a new order for the authenticated user. When items are given, they are priced from the catalog and the total is calculated from them. Tax is calculated from the tax rules of the shipping address and added to the total, or split out of it when prices include tax. The cost of the shipping method is added on top and is not taxed. The default shipping and billing addresses of the address book are used unless others are chosen. The order records the exchange rate of the currency it is placed in
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
-// @Param        request body models.Order true "Order data"
-// @Success      201  {object}  models.Order "Created order"
-// @Failure      400  {object}  models.ErrorResponse    "Invalid input"
-// @Failure      401  {object}  models.ErrorResponse    "Unauthorized"
-// @Failure      500  {object}  models.ErrorResponse    "Internal server error"
+// @Param        request body models.CreateOrderRequest true "Order data"
+// @Param        currency query string false "Currency to place the order in; defaults to the Accept-Currency header, then the store currency"
+// @Success      201  {object}  models.OrderResponse "Created order"
+// @Failure      400  {object}  models.Problem    "Invalid input, unknown address or shipping method"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem    "Unauthorized"
+// @Failure      403  {object}  models.Problem    "Email address not verified"
+// @Failure      409  {object}  models.Problem    "A product price or the shipping method is not in the store currency"
+// @Failure      500  {object}  models.Problem    "Internal server error"
 // @Security     Bearer
 // @Router       /api/orders [post]
 func CreateOrder(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	var order models.Order
 
-	if err := c.BodyParser(&order); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "Invalid input",
-		})
+	if config.EmailVerificationPolicy() != config.VerificationPolicyNone {
+		user, err := config.Users.FindByID(userID)
+		if err != nil {
+			return problem.NotFound("user_not_found", "User not found")
+		}
+		if !user.IsEmailVerified() {
+			return problem.Forbidden("email_not_verified", "Email address must be verified before placing orders")
+		}
+	}
+
+	var req models.CreateOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	rate, err := displayRate(c)
+	if err != nil {
+		return err
 	}
 
-	// Validate order total (should be positive)
-	if order.Total <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "Order total must be greater than 0",
-		})
+	// A total is only taken from orders without items, and only in the store currency
+	if len(req.Items) == 0 && (!req.Total.IsPositive() || req.Total.Currency != config.Currency()) {
+		return problem.Validation([]models.FieldError{{Field: "total", Rule: "money", Message: "must be a positive amount in " + config.Currency()}})
 	}
 
-	order.UserID = userID
-	order.Status = "pending"
+	order := req.Order(userID)
+	order.SnapshotRate(rate)
+
+	var addressProblem *problem.Error
+	if order.ShippingAddress, addressProblem = orderAddress(userID, req.ShippingAddressID, "default_shipping"); addressProblem != nil {
+		return addressProblem
+	}
+	if order.BillingAddress, addressProblem = orderAddress(userID, req.BillingAddressID, "default_billing"); addressProblem != nil {
+		return addressProblem
+	}
+	if order.BillingAddress.IsZero() {
+		order.BillingAddress = order.ShippingAddress
+	}
+	if order.ShippingMethod != "" && order.ShippingAddress.IsZero() {
+		return problem.BadRequest("shipping_address_required", "A shipping address is required to ship an order")
+	}
 
-	if err := config.DB.Create(&order).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to create order",
-		})
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		// Orders without items are taxed as a single uncategorized line
+		taxable := []tax.Item{{Amount: order.Total}}
+		if len(order.Items) > 0 {
+			var err error
+			if taxable, err = priceOrderItems(config.Products.WithTx(tx), &order); err != nil {
+				return err
+			}
+		}
+		goods := order.Total
+		if err := calculateOrderTax(tx, &order, taxable); err != nil {
+			return err
+		}
+		if err := applyOrderShipping(tx, &order, goods); err != nil {
+			return err
+		}
+		return config.Orders.WithTx(tx).Create(&order)
+	})
+	if errors.Is(err, errProductNotFound) {
+		return problem.BadRequest("ordered_product_not_found", "Ordered product not found")
+	}
+	if errors.Is(err, errVariantRequired) {
+		return problem.BadRequest("variant_required", "A variant must be selected for this product")
+	}
+	if errors.Is(err, errVariantNotFound) {
+		return problem.BadRequest("ordered_variant_not_found", "Ordered variant not found for this product")
+	}
+	if errors.Is(err, errInsufficientStock) {
+		return problem.BadRequest("insufficient_stock", "Insufficient stock for ordered product")
+	}
+	if errors.Is(err, errShippingMethodNotFound) {
+		return problem.BadRequest("shipping_method_not_found", "Shipping method not found")
+	}
+	if errors.Is(err, errCurrencyMismatch) {
+		return problem.Conflict("price_currency_mismatch", "A product price is not in the store currency")
+	}
+	if errors.Is(err, errShippingCurrencyMismatch) {
+		return problem.Conflict("shipping_currency_mismatch", "The shipping method is not priced in the store currency")
+	}
+	if err != nil {
+		return problem.Internal("Failed to create order")
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(order)
+	response := models.NewOrderResponse(order)
+	response.ConvertPrices(rate)
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+var (
+	errProductNotFound   = errors.New("product not found")
+	errInsufficientStock = errors.New("insufficient stock")
+	errVariantRequired   = errors.New("variant required")
+	errVariantNotFound   = errors.New("variant not found")
+	errCurrencyMismatch  = errors.New("product price is not in the store currency")
+)
+
+// priceOrderItems prices each item from the catalog, reserves its stock and
+// recalculates the order total so clients cannot choose their own prices.
+// Product stock is the total over all variants, so it is reserved as well.
+// It returns the order lines to be taxed, tagged with their product category.
+func priceOrderItems(products repository.ProductRepository, order *models.Order) ([]tax.Item, error) {
+	order.Total = money.New(0, config.Currency())
+	taxable := make([]tax.Item, 0, len(order.Items))
+	for i := range order.Items {
+		item := &order.Items[i]
+
+		product, err := products.FindByID(item.ProductID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, errProductNotFound
+			}
+			return nil, err
+		}
+
+		price := product.Price
+		item.SKU = ""
+
+		// Products with variants must be ordered as a specific variant
+		variantCount, err := products.CountVariants(product.ID)
+		if err != nil {
+			return nil, err
+		}
+		if variantCount > 0 {
+			if item.VariantID == nil {
+				return nil, errVariantRequired
+			}
+
+			variant, err := products.FindVariant(product.ID, *item.VariantID)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return nil, errVariantNotFound
+				}
+				return nil, err
+			}
+
+			reserved, err := products.ReserveVariantStock(variant.ID, item.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			if !reserved {
+				return nil, errInsufficientStock
+			}
+
+			price = variant.EffectivePrice(product.Price)
+			item.SKU = variant.SKU
+		} else if item.VariantID != nil {
+			return nil, errVariantNotFound
+		}
+
+		reserved, err := products.ReserveStock(product.ID, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		if !reserved {
+			return nil, errInsufficientStock
+		}
+
+		// Prices stored before the store switched currencies cannot be added to the rest
+		if price.Currency != order.Total.Currency {
+			return nil, errCurrencyMismatch
+		}
+
+		item.ID = 0
+		item.Product = models.Product{}
+		item.Price = price
+		item.WeightGrams = product.WeightGrams
+		lineTotal := price.Mul(int64(item.Quantity))
+		order.Total = order.Total.Add(lineTotal)
+		taxable = append(taxable, tax.Item{CategoryPath: []uint{product.CategoryID}, Amount: lineTotal})
+	}
+	return taxable, nil
 }
 
 // GetOrders - Protected endpoint to get user's orders
 // @Summary      Get user orders
-// @Description  Retrieve all orders for the authenticated user
+// @Description  Retrieve all orders for the authenticated user. Orders placed in the requested currency are shown with the exchange rate they were placed with
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
-// @Success      200  {array}   models.Order "List of user orders"
-// @Failure      401  {object}  models.ErrorResponse    "Unauthorized"
-// @Failure      500  {object}  models.ErrorResponse    "Internal server error"
+// @Param        currency query string false "Currency to show prices in; defaults to the Accept-Currency header, then the store currency"
+// @Success      200  {array}   models.OrderResponse "List of user orders"
+// @Failure      400  {object}  models.Problem    "Unsupported currency"
+// @Failure      401  {object}  models.Problem    "Unauthorized"
+// @Failure      500  {object}  models.Problem    "Internal server error"
 // @Security     Bearer
 // @Router       /api/orders [get]
 func GetOrders(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	var orders []models.Order
 
-	if err := config.DB.Where("user_id = ?", userID).Find(&orders).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to fetch orders",
-		})
+	rate, err := displayRate(c)
+	if err != nil {
+		return err
+	}
+
+	orders, err := config.Orders.ListByUser(userID)
+	if err != nil {
+		return problem.Internal("Failed to fetch orders")
 	}
 
-	return c.JSON(orders)
+	return c.JSON(orderResponses(orders, rate))
+}
+
+// GetAllOrders - Admin endpoint to list orders of all users
+// @Summary      List all orders
+// @Description  Retrieve a paginated list of all orders, newest first, optionally filtered by status. Requires an admin user or an API key with the orders:read scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        status  query     string  false  "Order status"
+// @Param        page    query     int     false  "Page number (default 1)"
+// @Param        limit   query     int     false  "Page size (default 10, max 100)"
+// @Param        currency query    string  false  "Currency to show amounts in; defaults to the Accept-Currency header, then the store currency"
+// @Success      200  {object}  models.OrderListResponse "Paginated orders"
+// @Failure      400  {object}  models.Problem     "Invalid pagination or unsupported currency"
+// @Failure      401  {object}  models.Problem     "Unauthorized"
+// @Failure      403  {object}  models.Problem     "Admin access or orders:read scope required"
+// @Failure      500  {object}  models.Problem     "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/orders [get]
+func GetAllOrders(c *fiber.Ctx) error {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return problem.BadRequest("invalid_pagination", "Invalid pagination parameters")
+	}
+
+	rate, err := displayRate(c)
+	if err != nil {
+		return err
+	}
+
+	filter := repository.OrderFilter{Status: c.Query("status")}
+	orders, total, err := config.Orders.List(filter, (page-1)*limit, limit)
+	if err != nil {
+		return problem.Internal("Failed to fetch orders")
+	}
+
+	return c.JSON(models.OrderListResponse{
+		Orders: orderResponses(orders, rate),
+		Pagination: models.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
 }
 
 // DeleteOrder - Protected endpoint to cancel order
 // @Summary      Cancel order
-// @Description  Cancel/delete a specific order for the authenticated user
+// @Description  Cancel one of the authenticated user's orders before anything was shipped. The stock is released. Nothing is charged before an order ships, so no refund is recorded. Shipped orders are sent back with a return request instead
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
 // @Param        id       path      int     true  "Order ID"
 // @Param        simulate query     string  false "Simulate error (400 for bad request)"
-// @Success      200  {object}  models.ErrorResponse "Order cancelled successfully"
-// @Failure      400  {object}  models.ErrorResponse "Invalid order ID"
-// @Failure      401  {object}  models.ErrorResponse "Unauthorized"
-// @Failure      404  {object}  models.ErrorResponse "Order not found"
-// @Failure      500  {object}  models.ErrorResponse "Internal server error"
+// @Success      200  {object}  models.MessageResponse "Order cancelled successfully"
+// @Failure      400  {object}  models.Problem "Invalid order ID"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      404  {object}  models.Problem "Order not found"
+// @Failure      409  {object}  models.Problem "Order can no longer be cancelled"
+// @Failure      500  {object}  models.Problem "Internal server error"
 // @Security     Bearer
 // @Router       /api/orders/{id} [delete]
 func DeleteOrder(c *fiber.Ctx) error {
@@ -253,29 +483,34 @@ func DeleteOrder(c *fiber.Ctx) error {
 
 	// Support simulating 400 error for testing
 	if c.Query("simulate") == "400" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "Invalid request for testing",
-		})
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid request for testing")
 	}
 
 	orderIDInt, err := strconv.Atoi(orderID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "Invalid order ID",
-		})
-	}
-
-	var order models.Order
-	if err := config.DB.Where("id = ? AND user_id = ?", orderIDInt, userID).First(&order).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
-			Error: "Order not found",
-		})
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid order ID")
 	}
 
-	if err := config.DB.Delete(&order).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to cancel order",
-		})
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		order, err := config.Orders.WithTx(tx).FindForUpdate(uint(orderIDInt))
+		if err != nil {
+			return err
+		}
+		if order.UserID != userID {
+			return repository.ErrNotFound
+		}
+		if order.Status != models.OrderStatusPending {
+			return errOrderNotCancellable
+		}
+		return cancelOrder(tx, order)
+	})
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return problem.NotFound("order_not_found", "Order not found")
+	case errors.Is(err, errOrderNotCancellable):
+		return problem.Conflict("order_not_cancellable", "Only orders that have not been shipped can be cancelled")
+	case err != nil:
+		return problem.Internal("Failed to cancel order")
 	}
 
 	return c.JSON(models.MessageResponse{
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"encoding/json"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"go-fiber-api/repository"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// fakeUsers is an in-memory UserRepository
+type fakeUsers struct {
+	users map[uint]*models.User
+}
+
+func (f *fakeUsers) WithTx(tx *gorm.DB) repository.UserRepository { return f }
+
+func (f *fakeUsers) FindByID(id uint) (*models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeUsers) FindByEmail(email string) (*models.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeUsers) FindByEmailFold(email string) (*models.User, error) {
+	for _, user := range f.users {
+		if strings.EqualFold(user.Email, email) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeUsers) EmailInUse(email string) (bool, error) {
+	_, err := f.FindByEmailFold(email)
+	return err == nil, nil
+}
+
+func (f *fakeUsers) Create(user *models.User) error {
+	user.ID = uint(len(f.users) + 1)
+	return f.Save(user)
+}
+
+func (f *fakeUsers) Save(user *models.User) error {
+	copied := *user
+	f.users[user.ID] = &copied
+	return nil
+}
+
+func (f *fakeUsers) Update(id uint, fields map[string]interface{}) error { return nil }
+
+func (f *fakeUsers) MarkEmailVerified(id uint, at time.Time) error { return nil }
+
+func (f *fakeUsers) AdvanceTOTPStep(id uint, step int64) (bool, error) { return true, nil }
+
+func withFakeUsers(t *testing.T, users ...models.User) *fakeUsers {
+	t.Helper()
+	fake := &fakeUsers{users: map[uint]*models.User{}}
+	for i := range users {
+		fake.users[users[i].ID] = &users[i]
+	}
+	previous := config.Users
+	config.Users = fake
+	t.Cleanup(func() { config.Users = previous })
+	return fake
+}
+
+// fakeTx runs transactions directly against the fake repositories, which ignore the handle
+type fakeTx struct{}
+
+func (fakeTx) Transaction(fn func(tx *gorm.DB) error) error { return fn(nil) }
+
+func withFakeTx(t *testing.T) {
+	t.Helper()
+	previous := config.Tx
+	config.Tx = fakeTx{}
+	t.Cleanup(func() { config.Tx = previous })
+}
+
+// profileApp serves the profile endpoints as the given user
+func profileApp(userID uint) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: problem.ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/api/profile", GetProfile)
+	app.Put("/api/profile", UpdateProfile)
+	return app
+}
+
+func TestGetProfileUsesUserRepository(t *testing.T) {
+	withFakeUsers(t, models.User{ID: 1, Email: "jane@example.com", FirstName: "Jane", Role: "user"})
+
+	resp, err := profileApp(1).Test(httptest.NewRequest("GET", "/api/profile", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var profile models.UserResponse
+	json.NewDecoder(resp.Body).Decode(&profile)
+	if resp.StatusCode != fiber.StatusOK || profile.Email != "jane@example.com" {
+		t.Errorf("Expected Jane's profile, got %d %+v", resp.StatusCode, profile)
+	}
+
+	resp, err = profileApp(2).Test(httptest.NewRequest("GET", "/api/profile", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var body models.Problem
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != fiber.StatusNotFound || body.Code != "user_not_found" {
+		t.Errorf("Expected user_not_found, got %d %+v", resp.StatusCode, body)
+	}
+}
+
+func TestUpdateProfileCannotChangeRole(t *testing.T) {
+	users := withFakeUsers(t, models.User{ID: 1, Email: "jane@example.com", FirstName: "Jane", LastName: "Doe", Role: "user"})
+
+	req := httptest.NewRequest("PUT", "/api/profile", strings.NewReader(`{"first_name": "Janet", "last_name": "Doe", "role": "admin", "id": 2}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := profileApp(1).Test(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	saved := users.users[1]
+	if saved.FirstName != "Janet" || saved.Role != "user" {
+		t.Errorf("Expected only the name to change, got %+v", saved)
+	}
+	if _, ok := users.users[2]; ok {
+		t.Error("Expected no other user to be written")
+	}
+}
+
+func TestRegisterRejectsEmailDifferingInCase(t *testing.T) {
+	withFakeUsers(t, models.User{ID: 1, Email: "jane@example.com", Role: "user"})
+	app := fiber.New(fiber.Config{ErrorHandler: problem.ErrorHandler})
+	app.Post("/auth/register", Register)
+
+	body := `{"email": "Jane@Example.com", "password": "password123", "first_name": "Jane", "last_name": "Doe"}`
+	req := httptest.NewRequest("POST", "/auth/register", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var problemBody models.Problem
+	json.NewDecoder(resp.Body).Decode(&problemBody)
+	if resp.StatusCode != fiber.StatusConflict || problemBody.Code != "email_taken" {
+		t.Errorf("Expected email_taken for an address differing only in case, got %d %+v", resp.StatusCode, problemBody)
+	}
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAPIKeys - Admin endpoint to list API keys
+// @Summary      List API keys
+// @Description  List all active API keys. The keys themselves are never returned after creation
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   models.APIKey        "API keys"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Admin access required"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Router       /api/admin/api-keys [get]
+func GetAPIKeys(c *fiber.Ctx) error {
+	keys := []models.APIKey{}
+	if err := config.DB.Order("created_at DESC").Find(&keys).Error; err != nil {
+		return problem.Internal("Failed to fetch API keys")
+	}
+	return c.JSON(keys)
+}
+
+// CreateAPIKey - Admin endpoint to create an API key
+// @Summary      Create API key
+// @Description  Create an API key with the given scopes (orders:read, orders:write, products:write, users:write, settings:read, settings:write) and optional expiry. The key is only shown in this response; send it in the X-API-Key header
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request body models.CreateAPIKeyRequest true "API key data"
+// @Success      201  {object}  models.CreateAPIKeyResponse "Created API key including the plaintext key"
+// @Failure      400  {object}  models.Problem        "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem        "Unauthorized"
+// @Failure      403  {object}  models.Problem        "Admin access required"
+// @Failure      500  {object}  models.Problem        "Internal server error"
+// @Security     Bearer
+// @Router       /api/admin/api-keys [post]
+func CreateAPIKey(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return problem.Validation([]models.FieldError{{Field: "expires_at", Rule: "future", Message: "must be in the future"}})
+	}
+
+	scopes := models.Scopes{}
+	for _, scope := range req.Scopes {
+		if !scopes.Has(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	raw, prefix, err := models.GenerateAPIKey()
+	if err != nil {
+		return problem.Internal("Could not generate API key")
+	}
+
+	key := models.APIKey{
+		Name:        req.Name,
+		Prefix:      prefix,
+		KeyHash:     models.HashAPIKey(raw),
+		Scopes:      scopes,
+		CreatedByID: userID,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := config.DB.Create(&key).Error; err != nil {
+		return problem.Internal("Could not create API key")
+	}
+
+	log.Printf("API key %d (%s) with scopes %v created by admin %d", key.ID, key.Prefix, key.Scopes, userID)
+	return c.Status(fiber.StatusCreated).JSON(models.CreateAPIKeyResponse{APIKey: key, Key: raw})
+}
+
+// DeleteAPIKey - Admin endpoint to revoke an API key
+// @Summary      Revoke API key
+// @Description  Revoke an API key; requests using it are rejected immediately
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "API key ID"
+// @Success      200  {object}  models.MessageResponse "API key revoked"
+// @Failure      400  {object}  models.Problem   "Invalid API key ID"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      403  {object}  models.Problem   "Admin access required"
+// @Failure      404  {object}  models.Problem   "API key not found"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Router       /api/admin/api-keys/{id} [delete]
+func DeleteAPIKey(c *fiber.Ctx) error {
+	keyID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid API key ID")
+	}
+
+	result := config.DB.Delete(&models.APIKey{}, keyID)
+	if result.Error != nil {
+		return problem.Internal("Could not revoke API key")
+	}
+	if result.RowsAffected == 0 {
+		return problem.NotFound("api_key_not_found", "API key not found")
+	}
+
+	log.Printf("API key %d revoked by %s", keyID, actor(c))
+	return c.JSON(models.MessageResponse{Message: "API key revoked successfully"})
+}
+
+// actor describes who made an admin request, for audit log lines
+func actor(c *fiber.Ctx) string {
+	if key, ok := c.Locals("apiKey").(*models.APIKey); ok {
+		return "API key " + strconv.FormatUint(uint64(key.ID), 10) + " (" + key.Name + ")"
+	}
+	if userID, ok := c.Locals("userID").(uint); ok {
+		return "admin " + strconv.FormatUint(uint64(userID), 10)
+	}
+	return "unknown"
+}
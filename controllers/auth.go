@@ -3,7 +3,10 @@ package controllers
 import (
 	"go-fiber-api/config"
 	"go-fiber-api/models"
-	"os"
+	"go-fiber-api/problem"
+	"go-fiber-api/tokens"
+	"log"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -27,46 +30,83 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required" example:"password123"`
 }
 
-func getJWTSecret() []byte {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key"
+// generateToken starts a session for the user on the requesting device and issues its access token
+func generateToken(c *fiber.Ctx, user models.User) (string, error) {
+	jti, err := tokens.NewID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserID:     user.ID,
+		TokenID:    jti,
+		UserAgent:  truncate(c.Get(fiber.HeaderUserAgent), 255),
+		IPAddress:  clientIP(c),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(config.AccessTokenTTL()),
 	}
-	return []byte(secret)
+	if err := config.DB.Create(&session).Error; err != nil {
+		return "", err
+	}
+	// Expired sessions are never shown again, so they are cleaned up as the user logs in
+	config.DB.Where("user_id = ? AND expires_at < ?", user.ID, now).Delete(&models.Session{})
+
+	return signToken(user, "", jti, now, session.ExpiresAt)
+}
+
+// signToken signs a token for the user; tokens with a purpose are not accepted as access tokens
+func signToken(user models.User, purpose, jti string, issuedAt, expiresAt time.Time) (string, error) {
+	return config.Keys.Sign(tokens.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			Issuer:    config.JWTIssuer(),
+			Audience:  jwt.ClaimStrings{config.JWTAudience()},
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			NotBefore: jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Role:    user.Role,
+		Purpose: purpose,
+	})
 }
 
 // Register handles user registration
 // @Summary      Register a new user
-// @Description  Register a new user with email, password, first name, and last name
+// @Description  Register a new user with email, password, first name, and last name. A verification link is emailed to the new address
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
 // @Param        request body RegisterRequest true "User registration data"
-// @Success      201  {object}  models.User "User created successfully"
-// @Failure      400  {object}  models.ErrorResponse   "Invalid input"
-// @Failure      409  {object}  models.ErrorResponse   "User already exists"
-// @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Success      201  {object}  models.UserResponse "User created successfully"
+// @Failure      400  {object}  models.Problem   "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      409  {object}  models.Problem   "User already exists"
+// @Failure      500  {object}  models.Problem   "Internal server error"
 // @Router       /auth/register [post]
 func Register(c *fiber.Ctx) error {
 	var req RegisterRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{Error: "Invalid input"})
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
 	}
 
-	// Validate required fields
-	if req.Email == "" || req.Password == "" || req.FirstName == "" || req.LastName == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{Error: "Email, password, first name, and last name are required"})
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
 	}
 
 	// Check if user already exists
-	var existingUser models.User
-	if err := config.DB.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
-		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{Error: "User with this email already exists"})
+	inUse, err := config.Users.EmailInUse(req.Email)
+	if err != nil {
+		return problem.Internal("Could not create user")
+	}
+	if inUse {
+		return problem.Conflict("email_taken", "User with this email already exists")
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{Error: "Could not hash password"})
+		return problem.Internal("Could not hash password")
 	}
 
 	user := models.User{
@@ -77,55 +117,77 @@ func Register(c *fiber.Ctx) error {
 		Role:      "user",
 	}
 
-	if err := config.DB.Create(&user).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{Error: "Could not create user"})
+	if err := config.Users.Create(&user); err != nil {
+		return problem.Internal("Could not create user")
 	}
 
-	// Clear password from response
-	user.Password = ""
-	return c.Status(fiber.StatusCreated).JSON(user)
+	// A failed email does not undo the registration; the user can request a new link
+	if err := sendVerificationEmail(c.Context(), user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.NewUserResponse(user))
 }
 
 // Login handles user login
 // @Summary      User login
-// @Description  Authenticate user and return JWT token
+// @Description  Authenticate user and return JWT token. For users with two-factor authentication the response is an MFA challenge (see models.MFAChallengeResponse) to complete at /auth/login/2fa
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
 // @Param        request body LoginRequest true "User login credentials"
 // @Success      200  {object}  models.TokenResponse "Login successful with token"
-// @Failure      400  {object}  models.ErrorResponse "Invalid input"
-// @Failure      401  {object}  models.ErrorResponse "Invalid credentials"
-// @Failure      500  {object}  models.ErrorResponse "Internal server error"
+// @Failure      400  {object}  models.Problem "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Invalid credentials"
+// @Failure      403  {object}  models.Problem "Email address not verified"
+// @Failure      429  {object}  models.Problem "Too many failed attempts, see Retry-After"
+// @Failure      500  {object}  models.Problem "Internal server error"
 // @Router       /auth/login [post]
 func Login(c *fiber.Ctx) error {
 	var req LoginRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{Error: "Invalid input"})
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
 	}
 
-	// Validate required fields
-	if req.Email == "" || req.Password == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{Error: "Invalid credentials"})
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
 	}
 
-	var dbUser models.User
-	if err := config.DB.Where("email = ?", req.Email).First(&dbUser).Error; err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{Error: "Invalid credentials"})
+	throttleKeys := loginThrottleKeys(c, req.Email)
+	if wait := loginLockedFor(throttleKeys); wait > 0 {
+		return tooManyLoginAttempts(c, wait)
+	}
+
+	dbUser, err := config.Users.FindByEmailFold(req.Email)
+	if err != nil {
+		recordLoginFailure(throttleKeys)
+		return problem.Unauthorized("invalid_credentials", "Invalid credentials")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(req.Password)); err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{Error: "Invalid credentials"})
+		recordLoginFailure(throttleKeys)
+		return problem.Unauthorized("invalid_credentials", "Invalid credentials")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": dbUser.ID,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
-	})
+	if config.EmailVerificationPolicy() == config.VerificationPolicyLogin && !dbUser.IsEmailVerified() {
+		return problem.Forbidden("email_not_verified", "Email address has not been verified")
+	}
+
+	// With two-factor authentication the failure count is only reset once the code is verified,
+	// otherwise alternating password and code guesses would never trigger a lockout
+	if dbUser.TwoFactorEnabled {
+		mfaToken, err := generateMFAToken(*dbUser)
+		if err != nil {
+			return problem.Internal("Could not generate token")
+		}
+		return c.JSON(models.MFAChallengeResponse{MFARequired: true, MFAToken: mfaToken})
+	}
+	resetLoginFailures(req.Email)
 
-	tokenString, err := token.SignedString(getJWTSecret())
+	tokenString, err := generateToken(c, *dbUser)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{Error: "Could not generate token"})
+		return problem.Internal("Could not generate token")
 	}
 
 	return c.JSON(models.TokenResponse{Token: tokenString})
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"errors"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"go-fiber-api/repository"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetCategoryProducts - Public endpoint to list products of a category subtree
+// @Summary      Get products in category
+// @Description  Retrieve all products in a category and in all of its descendant categories
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Param        id    path   int     true   "Category ID"
+// @Param        sort  query  string  false  "Sort order (price, -price, rating, -rating, reviews, newest)"
+// @Param        currency query string false "Currency to show prices in; defaults to the Accept-Currency header, then the store currency"
+// @Success      200  {array}   models.Product       "List of products"
+// @Failure      400  {object}  models.Problem "Invalid category ID, sort key or unsupported currency"
+// @Failure      404  {object}  models.Problem "Category not found"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Router       /api/categories/{id}/products [get]
+func GetCategoryProducts(c *fiber.Ctx) error {
+	categoryID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid category ID")
+	}
+
+	var category models.Category
+	if err := config.DB.First(&category, categoryID).Error; err != nil {
+		return problem.NotFound("category_not_found", "Category not found")
+	}
+
+	var categories []models.Category
+	if err := config.DB.Select("id", "parent_id").Find(&categories).Error; err != nil {
+		return problem.Internal("Failed to fetch categories")
+	}
+
+	filter := repository.ProductFilter{CategoryIDs: models.DescendantIDs(categories, category.ID)}
+	if sort := c.Query("sort"); sort != "" {
+		order, ok := productSortOrders[sort]
+		if !ok {
+			return problem.BadRequest("invalid_sort", "Invalid sort key")
+		}
+		filter.Order = order
+	}
+
+	rate, err := displayRate(c)
+	if err != nil {
+		return err
+	}
+
+	products, err := config.Products.List(filter)
+	if err != nil {
+		return problem.Internal("Failed to fetch products")
+	}
+	for i := range products {
+		products[i].ConvertPrices(rate)
+	}
+	return c.JSON(products)
+}
+
+// CreateCategory - Admin endpoint to create a category
+// @Summary      Create category
+// @Description  Create a category, optionally underneath a parent category. The slug is derived from the name when omitted
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Param        request body models.CategoryRequest true "Category data"
+// @Success      201  {object}  models.Category      "Created category"
+// @Failure      400  {object}  models.Problem "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Admin access or products:write scope required"
+// @Failure      409  {object}  models.Problem "Name or slug already in use"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/categories [post]
+func CreateCategory(c *fiber.Ctx) error {
+	var req models.CategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	category := models.Category{}
+	if err := applyCategoryRequest(&category, req); err != nil {
+		return err
+	}
+
+	if err := config.DB.Create(&category).Error; err != nil {
+		return problem.Internal("Failed to create category")
+	}
+	return c.Status(fiber.StatusCreated).JSON(category)
+}
+
+// UpdateCategory - Admin endpoint to rename or move a category
+// @Summary      Update category
+// @Description  Update a category's name, slug or parent. Moving a category underneath itself or one of its descendants is rejected
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                     true  "Category ID"
+// @Param        request body  models.CategoryRequest  true  "Category data"
+// @Success      200  {object}  models.Category      "Updated category"
+// @Failure      400  {object}  models.Problem "Invalid input or category cycle"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Admin access or products:write scope required"
+// @Failure      404  {object}  models.Problem "Category not found"
+// @Failure      409  {object}  models.Problem "Name or slug already in use"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/categories/{id} [put]
+func UpdateCategory(c *fiber.Ctx) error {
+	categoryID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid category ID")
+	}
+
+	var category models.Category
+	if err := config.DB.First(&category, categoryID).Error; err != nil {
+		return problem.NotFound("category_not_found", "Category not found")
+	}
+
+	var req models.CategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	if err := applyCategoryRequest(&category, req); err != nil {
+		return err
+	}
+
+	if err := config.DB.Save(&category).Error; err != nil {
+		return problem.Internal("Failed to update category")
+	}
+	return c.JSON(category)
+}
+
+// applyCategoryRequest validates a create/update request against the existing
+// categories and copies it onto the category, or returns the problem with the request.
+func applyCategoryRequest(category *models.Category, req models.CategoryRequest) *problem.Error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return problem.BadRequest("category_name_missing", "Category name is required")
+	}
+
+	slug := models.Slugify(req.Slug)
+	if slug == "" {
+		slug = models.Slugify(req.Name)
+	}
+	if slug == "" {
+		return problem.BadRequest("invalid_category_slug", "Category slug must contain letters or digits")
+	}
+
+	var conflicts int64
+	config.DB.Model(&models.Category{}).
+		Where("(name = ? OR slug = ?) AND id <> ?", req.Name, slug, category.ID).
+		Count(&conflicts)
+	if conflicts > 0 {
+		return problem.Conflict("category_exists", "A category with this name or slug already exists")
+	}
+
+	if req.ParentID != nil {
+		var parent models.Category
+		if err := config.DB.First(&parent, *req.ParentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return problem.BadRequest("parent_category_not_found", "Parent category not found")
+			}
+			return problem.Internal("Failed to fetch categories")
+		}
+
+		if category.ID != 0 {
+			var categories []models.Category
+			if err := config.DB.Select("id", "parent_id").Find(&categories).Error; err != nil {
+				return problem.Internal("Failed to fetch categories")
+			}
+			if models.CreatesCategoryCycle(categories, category.ID, parent.ID) {
+				return problem.BadRequest("category_cycle", "A category cannot be moved underneath itself or one of its descendants")
+			}
+		}
+	}
+
+	category.Name = req.Name
+	category.Slug = slug
+	category.ParentID = req.ParentID
+	return nil
+}
@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"errors"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/money"
+	"go-fiber-api/problem"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// headerAcceptCurrency lets clients ask for prices in their currency, e.g. "EUR, USD;q=0.5"
+const headerAcceptCurrency = "Accept-Currency"
+
+// acceptedCurrencies parses an Accept-Currency header into currency codes, most preferred first.
+// Entries with q=0 or an invalid code are ignored.
+func acceptedCurrencies(header string) []string {
+	type entry struct {
+		code string
+		q    float64
+	}
+	var entries []entry
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		code := strings.ToUpper(strings.TrimSpace(params[0]))
+		if !money.ValidCurrency(code) {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.TrimSpace(name) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			entries = append(entries, entry{code, q})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	codes := make([]string, 0, len(entries))
+	for _, e := range entries {
+		codes = append(codes, e.code)
+	}
+	return codes
+}
+
+// displayRate returns the rate for showing store prices in the currency the client asked for
+// with ?currency= or Accept-Currency, or the identity rate when it asked for none. An
+// unsupported ?currency= is an error; unsupported Accept-Currency entries are skipped.
+func displayRate(c *fiber.Ctx) (money.Rate, error) {
+	c.Vary(headerAcceptCurrency)
+	base := config.Currency()
+
+	if requested := c.Query("currency"); requested != "" {
+		rate, found, err := exchangeRate(base, strings.ToUpper(requested))
+		if err != nil {
+			return money.Rate{}, problem.Internal("Failed to fetch exchange rates")
+		}
+		if !found {
+			return money.Rate{}, problem.BadRequest("unsupported_currency", "Prices are not available in "+requested)
+		}
+		return rate, nil
+	}
+
+	for _, code := range acceptedCurrencies(c.Get(headerAcceptCurrency)) {
+		rate, found, err := exchangeRate(base, code)
+		if err != nil {
+			return money.Rate{}, problem.Internal("Failed to fetch exchange rates")
+		}
+		if found {
+			return rate, nil
+		}
+	}
+	return money.Identity(base), nil
+}
+
+// exchangeRate looks up the rate from the base currency to currency
+func exchangeRate(base, currency string) (money.Rate, bool, error) {
+	if currency == base {
+		return money.Identity(base), true, nil
+	}
+	if !money.ValidCurrency(currency) {
+		return money.Rate{}, false, nil
+	}
+
+	var stored models.ExchangeRate
+	err := config.DB.Where("currency = ?", currency).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return money.Rate{}, false, nil
+	}
+	if err != nil {
+		return money.Rate{}, false, err
+	}
+
+	rate, err := stored.MoneyRate(base)
+	if err != nil {
+		log.Printf("Ignoring invalid exchange rate %q for %s: %v", stored.Rate, currency, err)
+		return money.Rate{}, false, nil
+	}
+	return rate, true, nil
+}
+
+// orderResponses maps orders to responses shown with the display rate. Orders placed in the
+// display currency are converted with the rate recorded on the order, so customers see the
+// amounts they were charged rather than today's.
+func orderResponses(orders []models.Order, rate money.Rate) []models.OrderResponse {
+	responses := models.NewOrderResponses(orders)
+	for i, order := range orders {
+		if snapshot := order.Rate(); snapshot.To == rate.To {
+			responses[i].ConvertPrices(snapshot)
+		} else {
+			responses[i].ConvertPrices(rate)
+		}
+	}
+	return responses
+}
+
+// GetExchangeRates - Admin endpoint to list exchange rates
+// @Summary      List exchange rates
+// @Description  List the exchange rates from the store currency that prices can be shown in. Requires an admin user or an API key with the settings:read scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   models.ExchangeRate  "Exchange rates"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Admin access or settings:read scope required"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/exchange-rates [get]
+func GetExchangeRates(c *fiber.Ctx) error {
+	rates := []models.ExchangeRate{}
+	if err := config.DB.Order("currency").Find(&rates).Error; err != nil {
+		return problem.Internal("Failed to fetch exchange rates")
+	}
+	return c.JSON(rates)
+}
+
+// SetExchangeRate - Admin endpoint to create or update the exchange rate of a currency
+// @Summary      Set exchange rate
+// @Description  Set how many units of a currency one unit of the store currency buys, and the multiple of minor units converted prices are rounded to (e.g. 5 to round CHF to 0.05). Orders keep the rate they were placed with. Requires an admin user or an API key with the settings:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        currency  path  string                      true  "ISO 4217 currency code"
+// @Param        request   body  models.ExchangeRateRequest  true  "Exchange rate"
+// @Success      200  {object}  models.ExchangeRate  "Saved exchange rate"
+// @Failure      400  {object}  models.Problem "Invalid currency"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Admin access or settings:write scope required"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/exchange-rates/{currency} [put]
+func SetExchangeRate(c *fiber.Ctx) error {
+	currency := strings.ToUpper(c.Params("currency"))
+	if !money.ValidCurrency(currency) {
+		return problem.BadRequest("invalid_currency", "Invalid currency code")
+	}
+	if currency == config.Currency() {
+		return problem.BadRequest("store_currency_rate", "The store currency always has a rate of 1")
+	}
+
+	var req models.ExchangeRateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+	value, err := money.ParseRate(req.Rate)
+	if err != nil {
+		return problem.Validation([]models.FieldError{{Field: "rate", Rule: "rate", Message: "must be a positive decimal number with at most 10 decimal places"}})
+	}
+	if req.RoundingIncrement == 0 {
+		req.RoundingIncrement = 1
+	}
+
+	var rate models.ExchangeRate
+	if err := config.DB.Where("currency = ?", currency).First(&rate).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return problem.Internal("Failed to fetch exchange rates")
+	}
+	rate.Currency = currency
+	rate.Rate = money.FormatRate(value)
+	rate.RoundingIncrement = req.RoundingIncrement
+	if err := config.DB.Save(&rate).Error; err != nil {
+		return problem.Internal("Failed to save exchange rate")
+	}
+
+	log.Printf("Exchange rate %s %s set to %s by %s", config.Currency(), currency, rate.Rate, actor(c))
+	return c.JSON(rate)
+}
+
+// DeleteExchangeRate - Admin endpoint to remove the exchange rate of a currency
+// @Summary      Delete exchange rate
+// @Description  Stop offering prices in a currency. Existing orders keep the rate they were placed with. Requires an admin user or an API key with the settings:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        currency  path  string  true  "ISO 4217 currency code"
+// @Success      200  {object}  models.MessageResponse "Exchange rate deleted"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      403  {object}  models.Problem   "Admin access or settings:write scope required"
+// @Failure      404  {object}  models.Problem   "Exchange rate not found"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/exchange-rates/{currency} [delete]
+func DeleteExchangeRate(c *fiber.Ctx) error {
+	currency := strings.ToUpper(c.Params("currency"))
+
+	result := config.DB.Where("currency = ?", currency).Delete(&models.ExchangeRate{})
+	if result.Error != nil {
+		return problem.Internal("Failed to delete exchange rate")
+	}
+	if result.RowsAffected == 0 {
+		return problem.NotFound("exchange_rate_not_found", "Exchange rate not found")
+	}
+
+	log.Printf("Exchange rate %s %s deleted by %s", config.Currency(), currency, actor(c))
+	return c.JSON(models.MessageResponse{Message: "Exchange rate deleted successfully"})
+}
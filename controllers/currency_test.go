@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAcceptedCurrenciesOrdersByQuality(t *testing.T) {
+	cases := map[string][]string{
+		"":                            {},
+		"eur":                         {"EUR"},
+		"EUR, USD;q=0.5":              {"EUR", "USD"},
+		"USD;q=0.2, GBP, CHF;q=0.9":   {"GBP", "CHF", "USD"},
+		"EUR;q=0, JPY":                {"JPY"},
+		"euro, *, CHF; q=0.3":         {"CHF"},
+		"CAD;q=abc, AUD;level=1;q=.8": {"CAD", "AUD"},
+	}
+	for header, expected := range cases {
+		if got := acceptedCurrencies(header); !reflect.DeepEqual(got, expected) {
+			t.Errorf("acceptedCurrencies(%q) = %v, expected %v", header, got, expected)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-fiber-api/config"
+	"go-fiber-api/images"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"io"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UploadProductImage - Admin endpoint to upload a product image
+// @Summary      Upload product image
+// @Description  Upload a JPEG, PNG or GIF image for a product as multipart form field "image". The type is detected from the file content and a thumbnail is generated. Images over IMAGE_MAX_SIZE bytes or IMAGE_MAX_PIXELS pixels are rejected
+// @Tags         Products
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id     path      int   true  "Product ID"
+// @Param        image  formData  file  true  "Image file"
+// @Success      201  {object}  models.ProductImage  "Uploaded image"
+// @Failure      400  {object}  models.Problem "Missing or unreadable image"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Admin access or products:write scope required"
+// @Failure      404  {object}  models.Problem "Product not found"
+// @Failure      413  {object}  models.Problem "Image too large"
+// @Failure      415  {object}  models.Problem "Unsupported image type"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/products/{id}/images [post]
+func UploadProductImage(c *fiber.Ctx) error {
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid product ID")
+	}
+
+	product, err := config.Products.FindByID(uint(productID))
+	if err != nil {
+		return problem.NotFound("product_not_found", "Product not found")
+	}
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		return problem.BadRequest("image_missing", "Image file is required")
+	}
+	if fileHeader.Size > config.MaxImageSize {
+		return problem.New(fiber.StatusRequestEntityTooLarge, "image_too_large",
+			fmt.Sprintf("Image must not be larger than %d bytes", config.MaxImageSize))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return problem.BadRequest("image_unreadable", "Could not read image")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, config.MaxImageSize+1))
+	if err != nil {
+		return problem.BadRequest("image_unreadable", "Could not read image")
+	}
+	if int64(len(data)) > config.MaxImageSize {
+		return problem.New(fiber.StatusRequestEntityTooLarge, "image_too_large",
+			fmt.Sprintf("Image must not be larger than %d bytes", config.MaxImageSize))
+	}
+
+	contentType, ext, err := images.Sniff(data)
+	if err != nil {
+		return problem.New(fiber.StatusUnsupportedMediaType, "unsupported_image_type", "Only JPEG, PNG and GIF images are supported")
+	}
+
+	thumbnail, thumbnailType, width, height, err := images.Thumbnail(data, config.ThumbnailSize, config.MaxImagePixels)
+	if errors.Is(err, images.ErrTooManyPixels) {
+		return problem.New(fiber.StatusRequestEntityTooLarge, "image_too_large",
+			fmt.Sprintf("Image must not have more than %d pixels", config.MaxImagePixels))
+	}
+	if err != nil {
+		return problem.BadRequest("image_undecodable", "Could not decode image")
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return problem.Internal("Failed to store image")
+	}
+
+	thumbnailExt := ".jpg"
+	if thumbnailType == "image/png" {
+		thumbnailExt = ".png"
+	}
+
+	image := models.ProductImage{
+		ProductID:    product.ID,
+		Key:          fmt.Sprintf("products/%d/%s%s", product.ID, name, ext),
+		ThumbnailKey: fmt.Sprintf("products/%d/%s_thumb%s", product.ID, name, thumbnailExt),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		Width:        width,
+		Height:       height,
+	}
+	image.URL = config.Storage.URL(image.Key)
+	image.ThumbnailURL = config.Storage.URL(image.ThumbnailKey)
+
+	ctx := c.Context()
+	if err := config.Storage.Put(ctx, image.Key, data, contentType); err != nil {
+		log.Printf("Failed to store image %s: %v", image.Key, err)
+		return problem.Internal("Failed to store image")
+	}
+	if err := config.Storage.Put(ctx, image.ThumbnailKey, thumbnail, thumbnailType); err != nil {
+		log.Printf("Failed to store thumbnail %s: %v", image.ThumbnailKey, err)
+		config.Storage.Delete(ctx, image.Key)
+		return problem.Internal("Failed to store image")
+	}
+
+	var count int64
+	config.DB.Model(&models.ProductImage{}).Where("product_id = ?", product.ID).Count(&count)
+	image.Position = int(count) + 1
+
+	if err := config.DB.Create(&image).Error; err != nil {
+		config.Storage.Delete(ctx, image.Key)
+		config.Storage.Delete(ctx, image.ThumbnailKey)
+		return problem.Internal("Failed to save image")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(image)
+}
+
+// DeleteProductImage - Admin endpoint to remove a product image
+// @Summary      Delete product image
+// @Description  Remove a product image and its thumbnail from storage
+// @Tags         Products
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int  true  "Product ID"
+// @Param        imageId  path  int  true  "Image ID"
+// @Success      200  {object}  models.MessageResponse "Image deleted"
+// @Failure      400  {object}  models.Problem   "Invalid ID"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      403  {object}  models.Problem   "Admin access or products:write scope required"
+// @Failure      404  {object}  models.Problem   "Image not found"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/products/{id}/images/{imageId} [delete]
+func DeleteProductImage(c *fiber.Ctx) error {
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid product ID")
+	}
+	imageID, err := strconv.Atoi(c.Params("imageId"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid image ID")
+	}
+
+	var image models.ProductImage
+	if err := config.DB.Where("id = ? AND product_id = ?", imageID, productID).First(&image).Error; err != nil {
+		return problem.NotFound("image_not_found", "Image not found")
+	}
+
+	if err := config.DB.Delete(&image).Error; err != nil {
+		return problem.Internal("Failed to delete image")
+	}
+
+	for _, key := range []string{image.Key, image.ThumbnailKey} {
+		if err := config.Storage.Delete(c.Context(), key); err != nil {
+			log.Printf("Failed to delete stored image %s: %v", key, err)
+		}
+	}
+
+	return c.JSON(models.MessageResponse{
+		Message: "Image deleted successfully",
+	})
+}
+
+// randomName returns a random hex string used for stored file names
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"go-fiber-api/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetJWKS publishes the public keys that verify access tokens
+// @Summary      JSON Web Key Set
+// @Description  Public keys for verifying tokens issued by this API. Tokens name their key in the kid header; retired keys stay listed until tokens signed with them expire
+// @Tags         Authentication
+// @Produce      json
+// @Success      200  {object}  tokens.JWKS "Key set"
+// @Router       /.well-known/jwks.json [get]
+func GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(config.Keys.JWKS())
+}
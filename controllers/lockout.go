@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// clientIP returns the client address. A trusted proxy's X-Forwarded-For lists the addresses
+// the request passed through; the last one was added by the proxy and cannot be spoofed.
+func clientIP(c *fiber.Ctx) string {
+	ip := c.IP()
+	if i := strings.LastIndexByte(ip, ','); i >= 0 {
+		ip = ip[i+1:]
+	}
+	return strings.TrimSpace(ip)
+}
+
+// loginThrottleKeys returns the keys failed logins are tracked under: the account and the client IP
+func loginThrottleKeys(c *fiber.Ctx, email string) []string {
+	return []string{
+		models.LoginThrottleKeyForEmail(strings.ToLower(strings.TrimSpace(email))),
+		models.LoginThrottleKeyForIP(clientIP(c)),
+	}
+}
+
+// loginLockedFor returns how long logins for any of the keys remain locked, or zero
+func loginLockedFor(keys []string) time.Duration {
+	var throttles []models.LoginThrottle
+	config.DB.Where("key IN ? AND locked_until > ?", keys, time.Now()).Find(&throttles)
+
+	var remaining time.Duration
+	for _, throttle := range throttles {
+		if wait := time.Until(*throttle.LockedUntil); wait > remaining {
+			remaining = wait
+		}
+	}
+	return remaining
+}
+
+// tooManyLoginAttempts sets Retry-After in whole seconds and returns a 429 problem
+func tooManyLoginAttempts(c *fiber.Ctx, wait time.Duration) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return problem.New(fiber.StatusTooManyRequests, "too_many_login_attempts", "Too many failed login attempts, please try again later")
+}
+
+// recordLoginFailure counts a failed attempt for each key and locks keys that
+// reached their limit. Every failure beyond the limit doubles the lockout.
+func recordLoginFailure(keys []string) {
+	settings := config.LoginLockout()
+	now := time.Now()
+
+	for _, key := range keys {
+		limit := settings.MaxAttempts
+		if strings.HasPrefix(key, "ip:") {
+			limit = settings.MaxAttemptsPerIP
+		}
+
+		err := config.Tx.Transaction(func(tx *gorm.DB) error {
+			// Make sure the row exists so concurrent first failures all lock the same row
+			err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "key"}}, DoNothing: true}).
+				Create(&models.LoginThrottle{Key: key}).Error
+			if err != nil {
+				return err
+			}
+			var throttle models.LoginThrottle
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&throttle).Error; err != nil {
+				return err
+			}
+
+			if throttle.Expired(now, settings.Window) {
+				throttle.Failures = 0
+			}
+			throttle.Failures++
+			throttle.LastFailureAt = now
+
+			if throttle.Failures >= limit {
+				lockout := settings.BaseLockout << uint(throttle.Failures-limit)
+				if lockout > settings.MaxLockout || lockout <= 0 {
+					lockout = settings.MaxLockout
+				}
+				lockedUntil := now.Add(lockout)
+				throttle.LockedUntil = &lockedUntil
+				log.Printf("Login lockout: %s locked for %s after %d failed attempts", key, lockout, throttle.Failures)
+			}
+
+			return tx.Save(&throttle).Error
+		})
+		if err != nil {
+			log.Printf("Failed to record failed login for %s: %v", key, err)
+		}
+	}
+}
+
+// resetLoginFailures forgets the failed attempts of an account after a successful login.
+// The client IP keeps its count so one valid account cannot be used to reset it.
+func resetLoginFailures(email string) {
+	config.DB.Where("key = ?", models.LoginThrottleKeyForEmail(strings.ToLower(strings.TrimSpace(email)))).
+		Delete(&models.LoginThrottle{})
+}
+
+// UnlockUser - Admin endpoint to lift a login lockout
+// @Summary      Unlock user account
+// @Description  Clear the failed login attempts and any lockout of a user account
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "User ID"
+// @Success      200  {object}  models.MessageResponse "Account unlocked"
+// @Failure      400  {object}  models.Problem   "Invalid user ID"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      403  {object}  models.Problem   "Admin access or users:write scope required"
+// @Failure      404  {object}  models.Problem   "User not found"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/users/{id}/unlock [post]
+func UnlockUser(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid user ID")
+	}
+
+	user, err := config.Users.FindByID(uint(userID))
+	if err != nil {
+		return problem.NotFound("user_not_found", "User not found")
+	}
+
+	key := models.LoginThrottleKeyForEmail(strings.ToLower(user.Email))
+	if err := config.DB.Where("key = ?", key).Delete(&models.LoginThrottle{}).Error; err != nil {
+		return problem.Internal("Could not unlock account")
+	}
+
+	log.Printf("Login lockout: %s unlocked by %s", key, actor(c))
+	return c.JSON(models.MessageResponse{Message: "Account unlocked successfully"})
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func clientIPFor(t *testing.T, trusted []string, forwardedFor string) string {
+	t.Helper()
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor, EnableTrustedProxyCheck: true, TrustedProxies: trusted})
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(clientIP(c)) })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestClientIPTrustsOnlyConfiguredProxies(t *testing.T) {
+	// app.Test connects from 0.0.0.0
+	if ip := clientIPFor(t, nil, "203.0.113.7"); ip != "0.0.0.0" {
+		t.Errorf("Expected the header to be ignored from an untrusted peer, got %s", ip)
+	}
+	if ip := clientIPFor(t, []string{"0.0.0.0"}, "203.0.113.7"); ip != "203.0.113.7" {
+		t.Errorf("Expected the forwarded address from a trusted proxy, got %s", ip)
+	}
+	if ip := clientIPFor(t, []string{"0.0.0.0"}, "10.9.9.9, 203.0.113.7"); ip != "203.0.113.7" {
+		t.Errorf("Expected the address the proxy appended, not the client-supplied one, got %s", ip)
+	}
+}
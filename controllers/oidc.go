@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"errors"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/oidc"
+	"go-fiber-api/problem"
+	"go-fiber-api/repository"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// oidcLoginStateTTL is how long a user has to complete sign-in at the identity provider
+const oidcLoginStateTTL = 10 * time.Minute
+
+var (
+	errUnverifiedLocalAccount    = errors.New("local account email not verified")
+	errUnverifiedExternalAccount = errors.New("provider did not verify the email address")
+)
+
+// StartOIDCLogin redirects to an external identity provider
+// @Summary      Start external sign-in
+// @Description  Redirect to the identity provider's sign-in page using the authorization code flow with PKCE. The provider redirects back to /auth/oidc/{provider}/callback
+// @Tags         Authentication
+// @Param        provider  path  string  true  "Provider name, e.g. google"
+// @Success      302  "Redirect to the identity provider"
+// @Failure      404  {object}  models.Problem "Unknown provider"
+// @Failure      502  {object}  models.Problem "Identity provider unavailable"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Router       /auth/oidc/{provider}/start [get]
+func StartOIDCLogin(c *fiber.Ctx) error {
+	provider, ok := config.OIDCProviders[c.Params("provider")]
+	if !ok {
+		return problem.NotFound("unknown_identity_provider", "Unknown identity provider")
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			return problem.Internal("Could not start sign-in")
+		}
+		values[i] = value
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := provider.AuthCodeURL(c.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Printf("OIDC provider %s discovery failed: %v", provider.Name, err)
+		return problem.New(fiber.StatusBadGateway, "identity_provider_unavailable", "Identity provider is unavailable")
+	}
+
+	loginState := models.OIDCLoginState{
+		StateHash:    hashUserToken(state),
+		Provider:     provider.Name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcLoginStateTTL),
+	}
+	if err := config.Identities.CreateLoginState(&loginState); err != nil {
+		return problem.Internal("Could not start sign-in")
+	}
+	// Opportunistically clean up abandoned sign-ins
+	config.Identities.DeleteExpiredLoginStates(time.Now())
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// OIDCCallback completes an external sign-in
+// @Summary      Complete external sign-in
+// @Description  Exchange the authorization code, verify the ID token and sign in. The external account is linked to an existing user with the same verified email address, or a new user is created. Returns an MFA challenge instead of a token when the user has two-factor authentication enabled
+// @Tags         Authentication
+// @Produce      json
+// @Param        provider  path   string  true   "Provider name"
+// @Param        code      query  string  true   "Authorization code"
+// @Param        state     query  string  true   "State from the start request"
+// @Success      200  {object}  models.TokenResponse "Login successful with token"
+// @Failure      400  {object}  models.Problem "Invalid or expired sign-in state"
+// @Failure      401  {object}  models.Problem "Sign-in rejected by the provider or invalid ID token"
+// @Failure      403  {object}  models.Problem "Provider did not confirm the email address"
+// @Failure      404  {object}  models.Problem "Unknown provider"
+// @Failure      409  {object}  models.Problem "Existing account with unverified email"
+// @Failure      502  {object}  models.Problem "Identity provider unavailable"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Router       /auth/oidc/{provider}/callback [get]
+func OIDCCallback(c *fiber.Ctx) error {
+	provider, ok := config.OIDCProviders[c.Params("provider")]
+	if !ok {
+		return problem.NotFound("unknown_identity_provider", "Unknown identity provider")
+	}
+
+	// The state is single use: it is deleted whether or not the sign-in succeeds
+	loginState, err := config.Identities.ConsumeLoginState(hashUserToken(c.Query("state")), provider.Name)
+	if err != nil || time.Now().After(loginState.ExpiresAt) {
+		return problem.BadRequest("invalid_sign_in_state", "Invalid or expired sign-in state")
+	}
+
+	if providerError := c.Query("error"); providerError != "" {
+		return problem.Unauthorized("identity_provider_rejected", "Sign-in was rejected by the identity provider: "+providerError)
+	}
+	if c.Query("code") == "" {
+		return problem.BadRequest("authorization_code_missing", "Authorization code is required")
+	}
+
+	token, err := provider.Exchange(c.Context(), c.Query("code"), loginState.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC provider %s code exchange failed: %v", provider.Name, err)
+		return problem.New(fiber.StatusBadGateway, "identity_provider_failed", "Could not complete sign-in with the identity provider")
+	}
+
+	claims, err := provider.VerifyIDToken(c.Context(), token.IDToken, loginState.Nonce)
+	if err != nil {
+		log.Printf("OIDC provider %s returned an invalid ID token: %v", provider.Name, err)
+		return problem.Unauthorized("invalid_id_token", "Invalid ID token")
+	}
+
+	user, err := userForIdentity(provider.Name, claims)
+	switch {
+	case errors.Is(err, errUnverifiedLocalAccount):
+		return problem.Conflict("unverified_local_account",
+			"An account with this email exists but its address is not verified; verify it or reset the password before signing in with "+provider.Name)
+	case errors.Is(err, errUnverifiedExternalAccount):
+		return problem.Forbidden("external_email_unverified", "The identity provider did not confirm your email address")
+	case err != nil:
+		return problem.Internal("Could not sign in")
+	}
+
+	if user.TwoFactorEnabled {
+		mfaToken, err := generateMFAToken(*user)
+		if err != nil {
+			return problem.Internal("Could not generate token")
+		}
+		return c.JSON(models.MFAChallengeResponse{MFARequired: true, MFAToken: mfaToken})
+	}
+
+	tokenString, err := generateToken(c, *user)
+	if err != nil {
+		return problem.Internal("Could not generate token")
+	}
+	return c.JSON(models.TokenResponse{Token: tokenString})
+}
+
+// userForIdentity finds the user linked to the external account, linking or creating one by
+// verified email on first sign-in
+func userForIdentity(provider string, claims *oidc.IDTokenClaims) (*models.User, error) {
+	identity, err := config.Identities.FindIdentity(provider, claims.Subject)
+	if err == nil {
+		return config.Users.FindByID(identity.UserID)
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	// Linking by email is only safe when the provider vouches for the address
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !bool(claims.EmailVerified) {
+		return nil, errUnverifiedExternalAccount
+	}
+
+	var user *models.User
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		users := config.Users.WithTx(tx)
+		var err error
+		user, err = users.FindByEmailFold(email)
+		switch {
+		case err == nil:
+			// Someone could have registered the address without owning it; linking would
+			// hand them the account once the real owner signs in
+			if !user.IsEmailVerified() {
+				return errUnverifiedLocalAccount
+			}
+		case errors.Is(err, repository.ErrNotFound):
+			if user, err = newExternalUser(email, claims); err != nil {
+				return err
+			}
+			if err := users.Create(user); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return config.Identities.WithTx(tx).CreateIdentity(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    email,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("OIDC identity %s linked to user %d", provider, user.ID)
+	return user, nil
+}
+
+// newExternalUser builds a user for a first-time external sign-in. The password is random and
+// unknown to anyone; the user can set one through the password reset flow.
+func newExternalUser(email string, claims *oidc.IDTokenClaims) (*models.User, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword(random[:], bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName = claims.Name
+	}
+
+	now := time.Now()
+	return &models.User{
+		Email:           email,
+		Password:        string(hashedPassword),
+		FirstName:       firstName,
+		LastName:        lastName,
+		Role:            "user",
+		EmailVerifiedAt: &now,
+	}, nil
+}
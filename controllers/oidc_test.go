@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"encoding/json"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/oidc/oidctest"
+	"go-fiber-api/problem"
+	"go-fiber-api/repository"
+	"go-fiber-api/tokens"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// fakeIdentities is an in-memory IdentityRepository
+type fakeIdentities struct {
+	states     map[string]models.OIDCLoginState
+	identities []models.UserIdentity
+}
+
+func (f *fakeIdentities) WithTx(tx *gorm.DB) repository.IdentityRepository { return f }
+
+func (f *fakeIdentities) CreateLoginState(state *models.OIDCLoginState) error {
+	f.states[state.StateHash] = *state
+	return nil
+}
+
+func (f *fakeIdentities) ConsumeLoginState(stateHash, provider string) (*models.OIDCLoginState, error) {
+	state, ok := f.states[stateHash]
+	if !ok || state.Provider != provider {
+		return nil, repository.ErrNotFound
+	}
+	delete(f.states, stateHash)
+	return &state, nil
+}
+
+func (f *fakeIdentities) DeleteExpiredLoginStates(now time.Time) error { return nil }
+
+func (f *fakeIdentities) FindIdentity(provider, subject string) (*models.UserIdentity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeIdentities) CreateIdentity(identity *models.UserIdentity) error {
+	f.identities = append(f.identities, *identity)
+	return nil
+}
+
+func withFakeIdentities(t *testing.T) *fakeIdentities {
+	t.Helper()
+	fake := &fakeIdentities{states: map[string]models.OIDCLoginState{}}
+	previous := config.Identities
+	config.Identities = fake
+	t.Cleanup(func() { config.Identities = previous })
+	return fake
+}
+
+// withMockProvider registers the mock issuer's provider and a fresh signing key
+func withMockProvider(t *testing.T) *oidctest.Issuer {
+	t.Helper()
+	issuer := oidctest.NewIssuer(t)
+	config.OIDCProviders["mock"] = issuer.Provider()
+	t.Cleanup(func() { delete(config.OIDCProviders, "mock") })
+
+	key, err := tokens.GenerateEd25519Key()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key returned error: %v", err)
+	}
+	previous := config.Keys
+	config.Keys, _ = tokens.NewKeySet(key)
+	t.Cleanup(func() { config.Keys = previous })
+	return issuer
+}
+
+func oidcApp() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: problem.ErrorHandler})
+	app.Get("/auth/oidc/:provider/start", StartOIDCLogin)
+	app.Get("/auth/oidc/:provider/callback", OIDCCallback)
+	return app
+}
+
+func TestOIDCSignInLinksVerifiedAccount(t *testing.T) {
+	issuer := withMockProvider(t)
+	identities := withFakeIdentities(t)
+	withFakeTx(t)
+	verified := time.Now()
+	withFakeUsers(t, models.User{ID: 1, Email: "jane@example.com", Role: "user", EmailVerifiedAt: &verified, TwoFactorEnabled: true})
+	app := oidcApp()
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/auth/oidc/mock/start", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusFound {
+		t.Fatalf("Expected a redirect to the provider, got %d", resp.StatusCode)
+	}
+
+	issuer.SetClaims(nil)
+	code, state := issuer.Authorize(resp.Header.Get(fiber.HeaderLocation))
+	callback := "/auth/oidc/mock/callback?" + url.Values{"code": {code}, "state": {state}}.Encode()
+
+	resp, err = app.Test(httptest.NewRequest("GET", callback, nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var challenge models.MFAChallengeResponse
+	json.NewDecoder(resp.Body).Decode(&challenge)
+	if resp.StatusCode != fiber.StatusOK || !challenge.MFARequired {
+		t.Fatalf("Expected an MFA challenge, got %d %+v", resp.StatusCode, challenge)
+	}
+	if userID, err := parseMFAToken(challenge.MFAToken); err != nil || userID != 1 {
+		t.Errorf("Expected an MFA token for user 1, got %d %v", userID, err)
+	}
+	if len(identities.identities) != 1 || identities.identities[0].UserID != 1 || identities.identities[0].Subject != "external-42" {
+		t.Errorf("Expected the external account to be linked to user 1, got %+v", identities.identities)
+	}
+
+	// The state is single use
+	resp, err = app.Test(httptest.NewRequest("GET", callback, nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var body models.Problem
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != fiber.StatusBadRequest || body.Code != "invalid_sign_in_state" {
+		t.Errorf("Expected a replayed state to be rejected, got %d %+v", resp.StatusCode, body)
+	}
+}
+
+func TestOIDCCallbackRejectsUnknownProvider(t *testing.T) {
+	resp, err := oidcApp().Test(httptest.NewRequest("GET", "/auth/oidc/nope/callback?code=c&state=s", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown provider, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// parsePagination reads the page and limit query parameters, applying defaults
+func parsePagination(c *fiber.Ctx) (int, int, bool) {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		return 0, 0, false
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultPageSize)))
+	if err != nil || limit < 1 {
+		return 0, 0, false
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	return page, limit, true
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-fiber-api/config"
+	"go-fiber-api/mailer"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const passwordResetTTL = time.Hour
+
+// resetMailSlots bounds how many password reset emails are sent in the background at once, so
+// a flood of requests cannot pile up goroutines and SMTP connections
+var resetMailSlots = make(chan struct{}, 8)
+
+// ForgotPasswordRequest struct for requesting a password reset link
+// @Description Password reset link request payload
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest struct for setting a new password with a reset token
+// @Description Password reset request payload
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required" example:"3q2-7w...Zk.Qm9v..."`
+	Password string `json:"password" validate:"required,min=6" example:"newpassword123"`
+}
+
+// ForgotPassword emails a password reset link
+// @Summary      Request password reset
+// @Description  Email a single-use password reset link. Always responds with 202 so the response does not reveal whether an account exists
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ForgotPasswordRequest true "Account email"
+// @Success      202  {object}  models.MessageResponse "Reset link sent if the account exists"
+// @Failure      400  {object}  models.Problem   "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Router       /auth/forgot-password [post]
+func ForgotPassword(c *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	// Look up and mail in the background so the response time does not reveal whether the account
+	// exists. When all slots are busy the request is dropped; the response is the same either way.
+	select {
+	case resetMailSlots <- struct{}{}:
+		go func(email string) {
+			defer func() { <-resetMailSlots }()
+			sendPasswordResetEmail(email)
+		}(req.Email)
+	default:
+		log.Printf("Dropped password reset request: %d emails already in flight", cap(resetMailSlots))
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.MessageResponse{
+		Message: "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+func sendPasswordResetEmail(email string) {
+	user, err := config.Users.FindByEmailFold(email)
+	if err != nil {
+		return
+	}
+
+	token, err := issueUserToken(config.DB, user.ID, models.TokenPurposePasswordReset, "", passwordResetTTL)
+	if err != nil {
+		log.Printf("Failed to create password reset token for user %d: %v", user.ID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	link := config.PasswordResetURL() + "?token=" + url.QueryEscape(token)
+	err = config.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nA password reset was requested for your account. Open the link below to choose a new password:\n\n%s\n\nThe link expires in %d minutes. If you did not request this, you can ignore this email.\n",
+			user.FirstName, link, int(passwordResetTTL.Minutes())),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+	}
+}
+
+// ResetPassword sets a new password using a reset token
+// @Summary      Reset password
+// @Description  Set a new password with a single-use reset token. All existing sessions of the user are signed out
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ResetPasswordRequest true "Reset token and new password"
+// @Success      200  {object}  models.MessageResponse "Password reset"
+// @Failure      400  {object}  models.Problem   "Invalid input or invalid/expired token"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Router       /auth/reset-password [post]
+func ResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return problem.Internal("Could not hash password")
+	}
+
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeUserToken(tx, req.Token, models.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		users := config.Users.WithTx(tx)
+		if err := users.Update(token.UserID, map[string]interface{}{"password": string(hashedPassword)}); err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, token.UserID, 0); err != nil {
+			return err
+		}
+
+		// Receiving the reset email proves ownership of the address
+		if err := users.MarkEmailVerified(token.UserID, now); err != nil {
+			return err
+		}
+
+		// Other reset links and pending email changes, which whoever knew the old password may
+		// have requested, stop working once the password has changed
+		return revokeUserTokens(tx, token.UserID, models.TokenPurposePasswordReset, models.TokenPurposeEmailChange)
+	})
+	if errors.Is(err, errInvalidUserToken) {
+		return problem.BadRequest("invalid_reset_token", "Invalid or expired reset token")
+	}
+	if err != nil {
+		return problem.Internal("Could not reset password")
+	}
+
+	return c.JSON(models.MessageResponse{Message: "Password has been reset successfully"})
+}
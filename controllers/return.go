@@ -0,0 +1,390 @@
+package controllers
+
+import (
+	"errors"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"go-fiber-api/repository"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	errOrderNotCancellable = errors.New("order cannot be cancelled")
+	errOrderNotReturnable  = errors.New("order cannot be returned")
+	errInvalidReturn       = errors.New("invalid return")
+	errReturnResolved      = errors.New("return already resolved")
+	errInvalidRefund       = errors.New("invalid refund")
+)
+
+// releaseStock puts quantity of the order item back into the stock of its product and variant
+func releaseStock(products repository.ProductRepository, item models.OrderItem, quantity int) error {
+	if item.VariantID != nil {
+		if err := products.ReleaseVariantStock(*item.VariantID, quantity); err != nil {
+			return err
+		}
+	}
+	return products.ReleaseStock(item.ProductID, quantity)
+}
+
+// cancelOrder releases the stock of the locked order's items and marks the order cancelled.
+// Only pending orders are cancelled and nothing is charged before an order ships, so no
+// refund is recorded.
+func cancelOrder(tx *gorm.DB, order *models.Order) error {
+	products := config.Products.WithTx(tx)
+	for _, item := range order.Items {
+		if err := releaseStock(products, item, item.Quantity); err != nil {
+			return err
+		}
+	}
+	return config.Orders.WithTx(tx).UpdateStatus(order, models.OrderStatusCancelled)
+}
+
+// orderReturns loads the order's returns with their items and refunds, newest first
+func orderReturns(db *gorm.DB, orderID uint) ([]models.OrderReturn, error) {
+	returns := []models.OrderReturn{}
+	err := db.Preload("Items").Preload("Refund").Where("order_id = ?", orderID).Order("created_at DESC, id DESC").Find(&returns).Error
+	return returns, err
+}
+
+// GetOrderReturns - Protected endpoint to list the returns of one of the user's orders
+// @Summary      Get order returns
+// @Description  List the return requests of one of the authenticated user's orders with their status and refund, newest first
+// @Tags         Orders
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {array}   models.OrderReturn "Returns"
+// @Failure      400  {object}  models.Problem     "Invalid order ID"
+// @Failure      401  {object}  models.Problem     "Unauthorized"
+// @Failure      404  {object}  models.Problem     "Order not found"
+// @Failure      500  {object}  models.Problem     "Internal server error"
+// @Security     Bearer
+// @Router       /api/orders/{id}/returns [get]
+func GetOrderReturns(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid order ID")
+	}
+
+	order, err := config.Orders.FindForUser(uint(orderID), userID)
+	if err != nil {
+		return problem.NotFound("order_not_found", "Order not found")
+	}
+
+	returns, err := orderReturns(config.DB, order.ID)
+	if err != nil {
+		return problem.Internal("Failed to fetch returns")
+	}
+	return c.JSON(returns)
+}
+
+// CreateReturn - Protected endpoint to request a return of delivered items
+// @Summary      Request return
+// @Description  Ask to send back delivered items of one of the authenticated user's orders. Items can be returned in several requests, but no more than was delivered. An admin approves or rejects the request
+// @Tags         Orders
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                         true  "Order ID"
+// @Param        request body  models.CreateReturnRequest  true  "Return request"
+// @Success      201  {object}  models.OrderReturn "Requested return"
+// @Failure      400  {object}  models.Problem     "Invalid input"
+// @Failure      422  {object}  models.Problem     "Validation failed"
+// @Failure      401  {object}  models.Problem     "Unauthorized"
+// @Failure      404  {object}  models.Problem     "Order not found"
+// @Failure      409  {object}  models.Problem     "Order cannot be returned"
+// @Failure      500  {object}  models.Problem     "Internal server error"
+// @Security     Bearer
+// @Router       /api/orders/{id}/returns [post]
+func CreateReturn(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid order ID")
+	}
+
+	var req models.CreateReturnRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	var ret models.OrderReturn
+	var fields []models.FieldError
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		// The lock keeps concurrent requests from returning the same units twice
+		order, err := config.Orders.WithTx(tx).FindForUpdate(uint(orderID))
+		if err != nil {
+			return err
+		}
+		if order.UserID != userID {
+			return repository.ErrNotFound
+		}
+		if order.Status == models.OrderStatusCancelled {
+			return errOrderNotReturnable
+		}
+
+		shipments, err := orderShipments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		returns, err := orderReturns(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		ret, fields = req.Return(*order, models.ReturnableQuantities(*order, shipments, returns))
+		if fields != nil {
+			return errInvalidReturn
+		}
+		return tx.Create(&ret).Error
+	})
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return problem.NotFound("order_not_found", "Order not found")
+	case errors.Is(err, errOrderNotReturnable):
+		return problem.Conflict("order_not_returnable", "Cancelled orders cannot be returned")
+	case errors.Is(err, errInvalidReturn):
+		return problem.Validation(fields)
+	case err != nil:
+		return problem.Internal("Failed to request return")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(ret)
+}
+
+// GetReturns - Admin endpoint to list return requests
+// @Summary      List returns
+// @Description  Retrieve a paginated list of return requests of all users, newest first, optionally filtered by status. Requires an admin user or an API key with the orders:read scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        status  query     string  false  "Return status (requested, approved or rejected)"
+// @Param        page    query     int     false  "Page number (default 1)"
+// @Param        limit   query     int     false  "Page size (default 10, max 100)"
+// @Success      200  {object}  models.ReturnListResponse "Paginated returns"
+// @Failure      400  {object}  models.Problem     "Invalid pagination"
+// @Failure      401  {object}  models.Problem     "Unauthorized"
+// @Failure      403  {object}  models.Problem     "Admin access or orders:read scope required"
+// @Failure      500  {object}  models.Problem     "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/returns [get]
+func GetReturns(c *fiber.Ctx) error {
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return problem.BadRequest("invalid_pagination", "Invalid pagination parameters")
+	}
+
+	query := config.DB.Model(&models.OrderReturn{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return problem.Internal("Failed to fetch returns")
+	}
+
+	returns := []models.OrderReturn{}
+	if err := query.Preload("Items").Preload("Refund").
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&returns).Error; err != nil {
+		return problem.Internal("Failed to fetch returns")
+	}
+
+	return c.JSON(models.ReturnListResponse{
+		Returns: returns,
+		Pagination: models.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// ApproveReturn - Admin endpoint to approve a return request
+// @Summary      Approve return
+// @Description  Approve a requested return: the items go back into stock unless restock is false, and a refund is recorded against the order. The refund defaults to the items' share of what was paid for the goods, including tax but not shipping; a refund in the store currency can be given instead, up to what is left to refund. Requires an admin user or an API key with the orders:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                          true  "Return ID"
+// @Param        request body  models.ApproveReturnRequest  true  "Approval"
+// @Success      200  {object}  models.OrderReturn "Approved return"
+// @Failure      400  {object}  models.Problem     "Invalid input"
+// @Failure      422  {object}  models.Problem     "Validation failed"
+// @Failure      401  {object}  models.Problem     "Unauthorized"
+// @Failure      403  {object}  models.Problem     "Admin access or orders:write scope required"
+// @Failure      404  {object}  models.Problem     "Return not found"
+// @Failure      409  {object}  models.Problem     "Return already approved or rejected"
+// @Failure      500  {object}  models.Problem     "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/returns/{id}/approve [post]
+func ApproveReturn(c *fiber.Ctx) error {
+	returnID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid return ID")
+	}
+
+	var req models.ApproveReturnRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	var ret models.OrderReturn
+	var refundable string
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		order, err := resolvableReturn(tx, uint(returnID), &ret)
+		if err != nil {
+			return err
+		}
+
+		amount := order.ReturnRefund(ret.Items)
+		if req.Refund != nil {
+			amount = *req.Refund
+			remaining := order.Refundable()
+			if amount.Currency != remaining.Currency || amount.Amount < 0 || amount.Cmp(remaining) > 0 {
+				refundable = remaining.String()
+				return errInvalidRefund
+			}
+		}
+
+		if req.Restock == nil || *req.Restock {
+			items := make(map[uint]models.OrderItem, len(order.Items))
+			for _, item := range order.Items {
+				items[item.ID] = item
+			}
+			products := config.Products.WithTx(tx)
+			for _, item := range ret.Items {
+				if err := releaseStock(products, items[item.OrderItemID], item.Quantity); err != nil {
+					return err
+				}
+			}
+		}
+
+		if amount.IsPositive() {
+			order.AddRefund(amount)
+			ret.Refund = &models.Refund{OrderID: order.ID, ReturnID: &ret.ID, Amount: amount, Reason: "Return approved"}
+			if err := config.Orders.WithTx(tx).AddRefund(order, ret.Refund); err != nil {
+				return err
+			}
+		}
+		return resolveReturn(tx, &ret, models.ReturnStatusApproved, req.Note)
+	})
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return problem.NotFound("return_not_found", "Return not found")
+	case errors.Is(err, errReturnResolved):
+		return problem.Conflict("return_resolved", "The return was already approved or rejected")
+	case errors.Is(err, errInvalidRefund):
+		return problem.Validation([]models.FieldError{{Field: "refund", Rule: "refundable", Message: "must be a non-negative amount of at most " + refundable}})
+	case err != nil:
+		return problem.Internal("Failed to approve return")
+	}
+
+	log.Printf("Return %d of order %d approved by %s", ret.ID, ret.OrderID, actor(c))
+	return c.JSON(ret)
+}
+
+// RejectReturn - Admin endpoint to reject a return request
+// @Summary      Reject return
+// @Description  Reject a requested return. Its items can be requested for return again. Requires an admin user or an API key with the orders:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                         true  "Return ID"
+// @Param        request body  models.RejectReturnRequest  true  "Rejection"
+// @Success      200  {object}  models.OrderReturn "Rejected return"
+// @Failure      400  {object}  models.Problem     "Invalid input"
+// @Failure      422  {object}  models.Problem     "Validation failed"
+// @Failure      401  {object}  models.Problem     "Unauthorized"
+// @Failure      403  {object}  models.Problem     "Admin access or orders:write scope required"
+// @Failure      404  {object}  models.Problem     "Return not found"
+// @Failure      409  {object}  models.Problem     "Return already approved or rejected"
+// @Failure      500  {object}  models.Problem     "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/returns/{id}/reject [post]
+func RejectReturn(c *fiber.Ctx) error {
+	returnID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid return ID")
+	}
+
+	var req models.RejectReturnRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	var ret models.OrderReturn
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		if _, err := resolvableReturn(tx, uint(returnID), &ret); err != nil {
+			return err
+		}
+		return resolveReturn(tx, &ret, models.ReturnStatusRejected, req.Note)
+	})
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return problem.NotFound("return_not_found", "Return not found")
+	case errors.Is(err, errReturnResolved):
+		return problem.Conflict("return_resolved", "The return was already approved or rejected")
+	case err != nil:
+		return problem.Internal("Failed to reject return")
+	}
+
+	log.Printf("Return %d of order %d rejected by %s", ret.ID, ret.OrderID, actor(c))
+	return c.JSON(ret)
+}
+
+// resolvableReturn loads a requested return with its items into ret and locks its order,
+// which it returns
+func resolvableReturn(tx *gorm.DB, returnID uint, ret *models.OrderReturn) (*models.Order, error) {
+	var located models.OrderReturn
+	if err := tx.Select("order_id").First(&located, returnID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	// Returns are resolved under the order lock, so a return is never resolved twice
+	order, err := config.Orders.WithTx(tx).FindForUpdate(located.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Preload("Items").First(ret, returnID).Error; err != nil {
+		return nil, err
+	}
+	if ret.Status != models.ReturnStatusRequested {
+		return nil, errReturnResolved
+	}
+	return order, nil
+}
+
+// resolveReturn records the admin's decision on the return
+func resolveReturn(tx *gorm.DB, ret *models.OrderReturn, status, note string) error {
+	now := time.Now()
+	ret.Status = status
+	ret.Note = note
+	ret.ResolvedAt = &now
+	return tx.Omit("Items", "Refund").Save(ret).Error
+}
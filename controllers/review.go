@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"go-fiber-api/repository"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetProductReviews - Public endpoint to list reviews of a product
+// @Summary      Get product reviews
+// @Description  Retrieve a paginated list of reviews for a product, newest first
+// @Tags         Reviews
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int  true   "Product ID"
+// @Param        page   query     int  false  "Page number (default 1)"
+// @Param        limit  query     int  false  "Page size (default 10, max 100)"
+// @Success      200  {object}  models.ReviewListResponse "Paginated reviews"
+// @Failure      400  {object}  models.Problem      "Invalid product ID or pagination"
+// @Failure      404  {object}  models.Problem      "Product not found"
+// @Failure      500  {object}  models.Problem      "Internal server error"
+// @Router       /api/products/{id}/reviews [get]
+func GetProductReviews(c *fiber.Ctx) error {
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid product ID")
+	}
+
+	page, limit, ok := parsePagination(c)
+	if !ok {
+		return problem.BadRequest("invalid_pagination", "Invalid pagination parameters")
+	}
+
+	product, err := config.Products.FindByID(uint(productID))
+	if err != nil {
+		return problem.NotFound("product_not_found", "Product not found")
+	}
+
+	var total int64
+	if err := config.DB.Model(&models.Review{}).Where("product_id = ?", product.ID).Count(&total).Error; err != nil {
+		return problem.Internal("Failed to fetch reviews")
+	}
+
+	reviews := []models.Review{}
+	if err := config.DB.Where("product_id = ?", product.ID).
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&reviews).Error; err != nil {
+		return problem.Internal("Failed to fetch reviews")
+	}
+
+	return c.JSON(models.ReviewListResponse{
+		Reviews: reviews,
+		Pagination: models.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// CreateProductReview - Protected endpoint to review a purchased product
+// @Summary      Review a product
+// @Description  Add a rating (1-5) and text review for a product contained in one of the user's delivered orders
+// @Tags         Reviews
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                         true  "Product ID"
+// @Param        request  body      models.CreateReviewRequest  true  "Review data"
+// @Success      201  {object}  models.Review        "Created review"
+// @Failure      400  {object}  models.Problem "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Product not purchased or not yet delivered"
+// @Failure      404  {object}  models.Problem "Product not found"
+// @Failure      409  {object}  models.Problem "Product already reviewed"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Router       /api/products/{id}/reviews [post]
+func CreateProductReview(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid product ID")
+	}
+
+	var req models.CreateReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	product, err := config.Products.FindByID(uint(productID))
+	if err != nil {
+		return problem.NotFound("product_not_found", "Product not found")
+	}
+
+	// Only customers who actually received the product may review it
+	delivered, err := config.Orders.HasDeliveredProduct(userID, product.ID)
+	if err != nil {
+		return problem.Internal("Failed to create review")
+	}
+	if !delivered {
+		return problem.Forbidden("review_not_allowed", "Only customers with a delivered order containing this product can review it")
+	}
+
+	var existing int64
+	config.DB.Unscoped().Model(&models.Review{}).Where("product_id = ? AND user_id = ?", product.ID, userID).Count(&existing)
+	if existing > 0 {
+		return problem.Conflict("already_reviewed", "You have already reviewed this product")
+	}
+
+	review := models.Review{
+		ProductID: product.ID,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Text:      req.Text,
+	}
+
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		return updateProductRating(tx, product.ID)
+	})
+	if repository.IsUniqueViolation(err) {
+		// A concurrent request created the review after the check above
+		return problem.Conflict("already_reviewed", "You have already reviewed this product")
+	}
+	if err != nil {
+		return problem.Internal("Failed to create review")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(review)
+}
+
+// updateProductRating recalculates the denormalized rating aggregates of a product
+func updateProductRating(tx *gorm.DB, productID uint) error {
+	var stats struct {
+		Average float64
+		Count   int
+	}
+	if err := tx.Model(&models.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("product_id = ?", productID).
+		Scan(&stats).Error; err != nil {
+		return err
+	}
+
+	return config.Products.WithTx(tx).UpdateRating(productID, stats.Average, stats.Count)
+}
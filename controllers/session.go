@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetSessions - Protected endpoint to list where the user is signed in
+// @Summary      List sessions
+// @Description  List the authenticated user's active sessions, most recently used first. The session of the current request is marked as current
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   models.Session       "Active sessions"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/sessions [get]
+func GetSessions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	currentID, _ := c.Locals("sessionID").(uint)
+
+	sessions := []models.Session{}
+	if err := config.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return problem.Internal("Failed to fetch sessions")
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return c.JSON(sessions)
+}
+
+// DeleteSession - Protected endpoint to sign out a session
+// @Summary      Sign out session
+// @Description  Revoke one of the authenticated user's sessions. Revoking the current session signs out this device
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Session ID"
+// @Success      200  {object}  models.MessageResponse "Session signed out"
+// @Failure      400  {object}  models.Problem   "Invalid session ID"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      404  {object}  models.Problem   "Session not found"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/sessions/{id} [delete]
+func DeleteSession(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	sessionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid session ID")
+	}
+
+	result := config.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return problem.Internal("Could not sign out session")
+	}
+	if result.RowsAffected == 0 {
+		return problem.NotFound("session_not_found", "Session not found")
+	}
+
+	return c.JSON(models.MessageResponse{Message: "Session signed out successfully"})
+}
+
+// DeleteOtherSessions - Protected endpoint to sign out everywhere else
+// @Summary      Sign out other sessions
+// @Description  Revoke all of the authenticated user's sessions except the current one
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.MessageResponse "Other sessions signed out"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/sessions [delete]
+func DeleteOtherSessions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	currentID, _ := c.Locals("sessionID").(uint)
+
+	if err := revokeSessions(config.DB, userID, currentID); err != nil {
+		return problem.Internal("Could not sign out sessions")
+	}
+
+	return c.JSON(models.MessageResponse{Message: "Signed out of all other sessions"})
+}
+
+// revokeSessions signs the user out of every session except keepID (0 revokes all)
+func revokeSessions(tx *gorm.DB, userID, keepID uint) error {
+	return tx.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// truncate shortens s to at most n bytes, e.g. for client-supplied headers stored in the database.
+// It never cuts a character in half, which PostgreSQL would reject as invalid UTF-8.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
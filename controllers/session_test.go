@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateKeepsWholeCharacters(t *testing.T) {
+	if got := truncate("Mozilla", 255); got != "Mozilla" {
+		t.Errorf("Expected short values to be kept, got %q", got)
+	}
+
+	// 254 ASCII bytes followed by a two-byte character that would straddle the limit
+	userAgent := strings.Repeat("a", 254) + "é" + "tail"
+	got := truncate(userAgent, 255)
+	if !utf8.ValidString(got) || got != strings.Repeat("a", 254) {
+		t.Errorf("Expected the cut to step back before the split character, got %d bytes", len(got))
+	}
+}
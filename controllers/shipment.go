@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"errors"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"go-fiber-api/repository"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	errOrderNotShippable = errors.New("order cannot be shipped")
+	errInvalidShipment   = errors.New("invalid shipment")
+	errShipmentReturned  = errors.New("shipment items are being returned")
+)
+
+// shippable reports whether shipments may be recorded for an order in the given status
+func shippable(status string) bool {
+	switch status {
+	case models.OrderStatusPending, models.OrderStatusPartiallyShipped, models.OrderStatusShipped, models.OrderStatusDelivered:
+		return true
+	}
+	return false
+}
+
+// orderShipments loads the order's shipments with their items, oldest first
+func orderShipments(db *gorm.DB, orderID uint) ([]models.Shipment, error) {
+	shipments := []models.Shipment{}
+	err := db.Preload("Items").Where("order_id = ?", orderID).Order("shipped_at, id").Find(&shipments).Error
+	return shipments, err
+}
+
+// checkReturnsDelivered fails with errShipmentReturned when the shipments, as they are about to
+// be changed, no longer deliver every unit that is in a requested or approved return
+func checkReturnsDelivered(tx *gorm.DB, orderID uint, shipments []models.Shipment) error {
+	returns, err := orderReturns(tx, orderID)
+	if err != nil {
+		return err
+	}
+	delivered := models.ShippedQuantities(shipments, true)
+	for itemID, returned := range models.ReturnedQuantities(returns) {
+		if returned > delivered[itemID] {
+			return errShipmentReturned
+		}
+	}
+	return nil
+}
+
+// updateFulfillmentStatus sets the order status its shipments put it in
+func updateFulfillmentStatus(tx *gorm.DB, order *models.Order, shipments []models.Shipment) error {
+	status := models.FulfillmentStatus(order.Items, shipments)
+	if status == order.Status {
+		return nil
+	}
+	return config.Orders.WithTx(tx).UpdateStatus(order, status)
+}
+
+// GetOrderShipments - Protected endpoint to track the shipments of one of the user's orders
+// @Summary      Get order shipments
+// @Description  List the shipments of one of the authenticated user's orders with their carrier, tracking number and items, oldest first
+// @Tags         Orders
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {array}   models.Shipment "Shipments"
+// @Failure      400  {object}  models.Problem  "Invalid order ID"
+// @Failure      401  {object}  models.Problem  "Unauthorized"
+// @Failure      404  {object}  models.Problem  "Order not found"
+// @Failure      500  {object}  models.Problem  "Internal server error"
+// @Security     Bearer
+// @Router       /api/orders/{id}/shipments [get]
+func GetOrderShipments(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid order ID")
+	}
+
+	order, err := config.Orders.FindForUser(uint(orderID), userID)
+	if err != nil {
+		return problem.NotFound("order_not_found", "Order not found")
+	}
+
+	shipments, err := orderShipments(config.DB, order.ID)
+	if err != nil {
+		return problem.Internal("Failed to fetch shipments")
+	}
+	return c.JSON(shipments)
+}
+
+// GetAdminOrderShipments - Admin endpoint to list the shipments of any order
+// @Summary      List order shipments
+// @Description  List the shipments of an order, oldest first. Requires an admin user or an API key with the orders:read scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Order ID"
+// @Success      200  {array}   models.Shipment "Shipments"
+// @Failure      400  {object}  models.Problem  "Invalid order ID"
+// @Failure      401  {object}  models.Problem  "Unauthorized"
+// @Failure      403  {object}  models.Problem  "Admin access or orders:read scope required"
+// @Failure      404  {object}  models.Problem  "Order not found"
+// @Failure      500  {object}  models.Problem  "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/orders/{id}/shipments [get]
+func GetAdminOrderShipments(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid order ID")
+	}
+
+	order, err := config.Orders.Find(uint(orderID))
+	if err != nil {
+		return problem.NotFound("order_not_found", "Order not found")
+	}
+
+	shipments, err := orderShipments(config.DB, order.ID)
+	if err != nil {
+		return problem.Internal("Failed to fetch shipments")
+	}
+	return c.JSON(shipments)
+}
+
+// CreateShipment - Admin endpoint to record a shipment of an order
+// @Summary      Create shipment
+// @Description  Record that some or all of an order's items were handed to a carrier. An item can be split over several shipments, but no more than was ordered can be shipped. The order becomes partially_shipped, or shipped once every item is in a shipment. Orders placed without items are shipped as a whole by a shipment without items. Orders marked shipped or delivered before shipments were recorded cannot get shipments. Requires an admin user or an API key with the orders:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                           true  "Order ID"
+// @Param        request body  models.CreateShipmentRequest  true  "Shipment data"
+// @Success      201  {object}  models.Shipment "Created shipment"
+// @Failure      400  {object}  models.Problem  "Invalid input"
+// @Failure      422  {object}  models.Problem  "Validation failed"
+// @Failure      401  {object}  models.Problem  "Unauthorized"
+// @Failure      403  {object}  models.Problem  "Admin access or orders:write scope required"
+// @Failure      404  {object}  models.Problem  "Order not found"
+// @Failure      409  {object}  models.Problem  "Order cannot be shipped"
+// @Failure      500  {object}  models.Problem  "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/orders/{id}/shipments [post]
+func CreateShipment(c *fiber.Ctx) error {
+	orderID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid order ID")
+	}
+
+	var req models.CreateShipmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	var shipment models.Shipment
+	var fields []models.FieldError
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		// The lock keeps concurrent shipments from shipping the same units twice
+		order, err := config.Orders.WithTx(tx).FindForUpdate(uint(orderID))
+		if err != nil {
+			return err
+		}
+		if !shippable(order.Status) {
+			return errOrderNotShippable
+		}
+
+		shipments, err := orderShipments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if order.FulfilledWithoutShipments(shipments) {
+			return errOrderNotShippable
+		}
+		shipment, fields = req.Shipment(*order, models.ShippedQuantities(shipments, false), time.Now())
+		if fields != nil {
+			return errInvalidShipment
+		}
+
+		if err := tx.Create(&shipment).Error; err != nil {
+			return err
+		}
+		return updateFulfillmentStatus(tx, order, append(shipments, shipment))
+	})
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return problem.NotFound("order_not_found", "Order not found")
+	case errors.Is(err, errOrderNotShippable):
+		return problem.Conflict("order_not_shippable", "Shipments cannot be recorded for an order in this status")
+	case errors.Is(err, errInvalidShipment):
+		return problem.Validation(fields)
+	case err != nil:
+		return problem.Internal("Failed to create shipment")
+	}
+
+	log.Printf("Shipment %d of order %d created by %s", shipment.ID, shipment.OrderID, actor(c))
+	return c.Status(fiber.StatusCreated).JSON(shipment)
+}
+
+// UpdateShipment - Admin endpoint to update tracking details or record delivery
+// @Summary      Update shipment
+// @Description  Change a shipment's carrier and tracking number, record its delivery with delivered_at, or undo a delivery recorded by mistake with clear_delivered_at. Omitted dates are kept. A delivery cannot be undone while the items are in a requested or approved return. The order becomes delivered once every item is in a delivered shipment. Requires an admin user or an API key with the orders:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                           true  "Shipment ID"
+// @Param        request body  models.UpdateShipmentRequest  true  "Shipment data"
+// @Success      200  {object}  models.Shipment "Updated shipment"
+// @Failure      400  {object}  models.Problem  "Invalid input"
+// @Failure      422  {object}  models.Problem  "Validation failed"
+// @Failure      401  {object}  models.Problem  "Unauthorized"
+// @Failure      403  {object}  models.Problem  "Admin access or orders:write scope required"
+// @Failure      404  {object}  models.Problem  "Shipment not found"
+// @Failure      409  {object}  models.Problem  "Shipment items are being returned"
+// @Failure      500  {object}  models.Problem  "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/shipments/{id} [put]
+func UpdateShipment(c *fiber.Ctx) error {
+	shipmentID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid shipment ID")
+	}
+
+	var req models.UpdateShipmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	var shipment models.Shipment
+	if err := config.DB.Preload("Items").First(&shipment, shipmentID).Error; err != nil {
+		return problem.NotFound("shipment_not_found", "Shipment not found")
+	}
+
+	req.Apply(&shipment)
+	if shipment.DeliveredAt != nil && shipment.DeliveredAt.Before(shipment.ShippedAt) {
+		return problem.Validation([]models.FieldError{{Field: "delivered_at", Rule: "shipped_at", Message: "must not be before shipped_at"}})
+	}
+
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		order, err := config.Orders.WithTx(tx).FindForUpdate(shipment.OrderID)
+		if err != nil {
+			return err
+		}
+		shipments, err := orderShipments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		for i := range shipments {
+			if shipments[i].ID == shipment.ID {
+				shipments[i] = shipment
+			}
+		}
+		if err := checkReturnsDelivered(tx, order.ID, shipments); err != nil {
+			return err
+		}
+
+		if err := tx.Omit("Items").Save(&shipment).Error; err != nil {
+			return err
+		}
+		if !shippable(order.Status) {
+			return nil
+		}
+		return updateFulfillmentStatus(tx, order, shipments)
+	})
+	switch {
+	case errors.Is(err, errShipmentReturned):
+		return problem.Conflict("shipment_returned", "Items of this shipment are in a return, so it must stay delivered")
+	case err != nil:
+		return problem.Internal("Failed to update shipment")
+	}
+
+	log.Printf("Shipment %d of order %d updated by %s", shipment.ID, shipment.OrderID, actor(c))
+	return c.JSON(shipment)
+}
+
+// DeleteShipment - Admin endpoint to remove a shipment recorded by mistake
+// @Summary      Delete shipment
+// @Description  Remove a shipment recorded by mistake. Its items count as unshipped again and the order status follows. Shipments whose items are in a requested or approved return cannot be deleted. Requires an admin user or an API key with the orders:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Shipment ID"
+// @Success      200  {object}  models.MessageResponse "Shipment deleted"
+// @Failure      400  {object}  models.Problem   "Invalid shipment ID"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      403  {object}  models.Problem   "Admin access or orders:write scope required"
+// @Failure      404  {object}  models.Problem   "Shipment not found"
+// @Failure      409  {object}  models.Problem   "Shipment items are being returned"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/shipments/{id} [delete]
+func DeleteShipment(c *fiber.Ctx) error {
+	shipmentID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid shipment ID")
+	}
+
+	var shipment models.Shipment
+	if err := config.DB.First(&shipment, shipmentID).Error; err != nil {
+		return problem.NotFound("shipment_not_found", "Shipment not found")
+	}
+
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		order, err := config.Orders.WithTx(tx).FindForUpdate(shipment.OrderID)
+		if err != nil {
+			return err
+		}
+		shipments, err := orderShipments(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		remaining := make([]models.Shipment, 0, len(shipments))
+		for _, other := range shipments {
+			if other.ID != shipment.ID {
+				remaining = append(remaining, other)
+			}
+		}
+		if err := checkReturnsDelivered(tx, order.ID, remaining); err != nil {
+			return err
+		}
+
+		if err := tx.Where("shipment_id = ?", shipment.ID).Delete(&models.ShipmentItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&shipment).Error; err != nil {
+			return err
+		}
+		if !shippable(order.Status) {
+			return nil
+		}
+		return updateFulfillmentStatus(tx, order, remaining)
+	})
+	switch {
+	case errors.Is(err, errShipmentReturned):
+		return problem.Conflict("shipment_returned", "Items of this shipment are in a return, so it cannot be deleted")
+	case err != nil:
+		return problem.Internal("Failed to delete shipment")
+	}
+
+	log.Printf("Shipment %d of order %d deleted by %s", shipment.ID, shipment.OrderID, actor(c))
+	return c.JSON(models.MessageResponse{Message: "Shipment deleted successfully"})
+}
@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"errors"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/money"
+	"go-fiber-api/problem"
+	"go-fiber-api/shipping"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	errShippingMethodNotFound   = errors.New("shipping method not found")
+	errShippingCurrencyMismatch = errors.New("shipping method is not priced in the store currency")
+)
+
+// applyOrderShipping prices the order's shipping method for its items and adds the cost to the
+// total. goods is the value of the items, which decides free shipping.
+func applyOrderShipping(tx *gorm.DB, order *models.Order, goods money.Money) error {
+	if order.ShippingMethod == "" {
+		return nil
+	}
+
+	var method models.ShippingMethod
+	if err := tx.Where("code = ?", order.ShippingMethod).First(&method).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errShippingMethodNotFound
+		}
+		return err
+	}
+	calculator, err := method.Calculator()
+	if err != nil {
+		return err
+	}
+
+	parcel := shipping.Parcel{Subtotal: goods}
+	for _, item := range order.Items {
+		parcel.WeightGrams += int64(item.WeightGrams) * int64(item.Quantity)
+	}
+
+	cost := calculator.Cost(parcel)
+	if cost.Currency != order.Total.Currency {
+		return errShippingCurrencyMismatch
+	}
+	order.ShippingCost = cost
+	order.Total = order.Total.Add(cost)
+	return nil
+}
+
+// GetShippingMethods - Public endpoint to list shipping methods
+// @Summary      List shipping methods
+// @Description  List the shipping methods orders can be placed with and how they are priced
+// @Tags         Orders
+// @Accept       json
+// @Produce      json
+// @Param        currency query string false "Currency to show prices in; defaults to the Accept-Currency header, then the store currency"
+// @Success      200  {array}   models.ShippingMethod "Shipping methods"
+// @Failure      400  {object}  models.Problem  "Unsupported currency"
+// @Failure      500  {object}  models.Problem  "Internal server error"
+// @Router       /api/shipping-methods [get]
+func GetShippingMethods(c *fiber.Ctx) error {
+	rate, err := displayRate(c)
+	if err != nil {
+		return err
+	}
+
+	methods := []models.ShippingMethod{}
+	if err := config.DB.Order("price_amount, name").Find(&methods).Error; err != nil {
+		return problem.Internal("Failed to fetch shipping methods")
+	}
+	for i := range methods {
+		methods[i].ConvertPrices(rate)
+	}
+	return c.JSON(methods)
+}
+
+// CreateShippingMethod - Admin endpoint to create a shipping method
+// @Summary      Create shipping method
+// @Description  Create a shipping method priced by a calculator: flat (price), weight (price plus price_per_kg for every started kilogram) or free_over_threshold (price below free_threshold, free from it on). Amounts are in the store currency. Requires an admin user or an API key with the settings:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ShippingMethodRequest true "Shipping method data"
+// @Success      201  {object}  models.ShippingMethod "Created shipping method"
+// @Failure      400  {object}  models.Problem  "Invalid input"
+// @Failure      422  {object}  models.Problem  "Validation failed"
+// @Failure      401  {object}  models.Problem  "Unauthorized"
+// @Failure      403  {object}  models.Problem  "Admin access or settings:write scope required"
+// @Failure      409  {object}  models.Problem  "Code already in use"
+// @Failure      500  {object}  models.Problem  "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/shipping-methods [post]
+func CreateShippingMethod(c *fiber.Ctx) error {
+	var req models.ShippingMethodRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	method := models.ShippingMethod{}
+	if err := applyShippingMethodRequest(&method, req); err != nil {
+		return err
+	}
+
+	if err := config.DB.Create(&method).Error; err != nil {
+		return problem.Internal("Failed to create shipping method")
+	}
+
+	log.Printf("Shipping method %d (%s) created by %s", method.ID, method.Code, actor(c))
+	return c.Status(fiber.StatusCreated).JSON(method)
+}
+
+// UpdateShippingMethod - Admin endpoint to update a shipping method
+// @Summary      Update shipping method
+// @Description  Update a shipping method. Orders keep the shipping cost they were placed with. Requires an admin user or an API key with the settings:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                           true  "Shipping method ID"
+// @Param        request body  models.ShippingMethodRequest  true  "Shipping method data"
+// @Success      200  {object}  models.ShippingMethod "Updated shipping method"
+// @Failure      400  {object}  models.Problem  "Invalid input"
+// @Failure      422  {object}  models.Problem  "Validation failed"
+// @Failure      401  {object}  models.Problem  "Unauthorized"
+// @Failure      403  {object}  models.Problem  "Admin access or settings:write scope required"
+// @Failure      404  {object}  models.Problem  "Shipping method not found"
+// @Failure      409  {object}  models.Problem  "Code already in use"
+// @Failure      500  {object}  models.Problem  "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/shipping-methods/{id} [put]
+func UpdateShippingMethod(c *fiber.Ctx) error {
+	methodID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid shipping method ID")
+	}
+
+	var method models.ShippingMethod
+	if err := config.DB.First(&method, methodID).Error; err != nil {
+		return problem.NotFound("shipping_method_not_found", "Shipping method not found")
+	}
+
+	var req models.ShippingMethodRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if err := applyShippingMethodRequest(&method, req); err != nil {
+		return err
+	}
+
+	if err := config.DB.Save(&method).Error; err != nil {
+		return problem.Internal("Failed to update shipping method")
+	}
+
+	log.Printf("Shipping method %d (%s) updated by %s", method.ID, method.Code, actor(c))
+	return c.JSON(method)
+}
+
+// DeleteShippingMethod - Admin endpoint to delete a shipping method
+// @Summary      Delete shipping method
+// @Description  Stop offering a shipping method. Orders keep the shipping cost they were placed with. Requires an admin user or an API key with the settings:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Shipping method ID"
+// @Success      200  {object}  models.MessageResponse "Shipping method deleted"
+// @Failure      400  {object}  models.Problem   "Invalid shipping method ID"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      403  {object}  models.Problem   "Admin access or settings:write scope required"
+// @Failure      404  {object}  models.Problem   "Shipping method not found"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/shipping-methods/{id} [delete]
+func DeleteShippingMethod(c *fiber.Ctx) error {
+	methodID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid shipping method ID")
+	}
+
+	result := config.DB.Delete(&models.ShippingMethod{}, methodID)
+	if result.Error != nil {
+		return problem.Internal("Failed to delete shipping method")
+	}
+	if result.RowsAffected == 0 {
+		return problem.NotFound("shipping_method_not_found", "Shipping method not found")
+	}
+
+	log.Printf("Shipping method %d deleted by %s", methodID, actor(c))
+	return c.JSON(models.MessageResponse{Message: "Shipping method deleted successfully"})
+}
+
+// SetProductWeight - Admin endpoint to set the shipping weight of a product
+// @Summary      Set product weight
+// @Description  Set the weight of one unit of a product in grams, which weight-based shipping methods charge by. Orders keep the weight their items had when they were placed. Requires an admin user or an API key with the products:write scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                          true  "Product ID"
+// @Param        request body  models.ProductWeightRequest  true  "Product weight"
+// @Success      200  {object}  models.Product "Updated product"
+// @Failure      400  {object}  models.Problem  "Invalid input"
+// @Failure      422  {object}  models.Problem  "Validation failed"
+// @Failure      401  {object}  models.Problem  "Unauthorized"
+// @Failure      403  {object}  models.Problem  "Admin access or products:write scope required"
+// @Failure      404  {object}  models.Problem  "Product not found"
+// @Failure      500  {object}  models.Problem  "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/products/{id}/weight [put]
+func SetProductWeight(c *fiber.Ctx) error {
+	productID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid product ID")
+	}
+
+	var req models.ProductWeightRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	product, err := config.Products.FindByID(uint(productID))
+	if err != nil {
+		return problem.NotFound("product_not_found", "Product not found")
+	}
+	if err := config.Products.UpdateWeight(product.ID, *req.WeightGrams); err != nil {
+		return problem.Internal("Failed to update product weight")
+	}
+	product.WeightGrams = *req.WeightGrams
+
+	log.Printf("Weight of product %d set to %d g by %s", product.ID, product.WeightGrams, actor(c))
+	return c.JSON(product)
+}
+
+// applyShippingMethodRequest validates a create/update request and copies it onto the method,
+// or returns the problem with the request
+func applyShippingMethodRequest(method *models.ShippingMethod, req models.ShippingMethodRequest) *problem.Error {
+	req.Code = strings.ToLower(strings.TrimSpace(req.Code))
+	req.Name = strings.TrimSpace(req.Name)
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	// Every amount the type uses must be a non-negative amount in the store currency
+	type amount struct {
+		field string
+		value *money.Money
+	}
+	amounts := []amount{{"price", &req.Price}}
+	switch req.Type {
+	case shipping.TypeWeight:
+		amounts = append(amounts, amount{"price_per_kg", req.PricePerKg})
+	case shipping.TypeFreeOverThreshold:
+		amounts = append(amounts, amount{"free_threshold", req.FreeThreshold})
+	}
+
+	var fields []models.FieldError
+	for _, a := range amounts {
+		if a.value.Currency != config.Currency() || a.value.Amount < 0 {
+			fields = append(fields, models.FieldError{Field: a.field, Rule: "money", Message: "must be a non-negative amount in " + config.Currency()})
+		}
+	}
+	if fields != nil {
+		return problem.Validation(fields)
+	}
+
+	var conflicts int64
+	config.DB.Model(&models.ShippingMethod{}).Where("code = ? AND id <> ?", req.Code, method.ID).Count(&conflicts)
+	if conflicts > 0 {
+		return problem.Conflict("shipping_method_exists", "A shipping method with this code already exists")
+	}
+
+	req.Apply(method)
+	return nil
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"errors"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"go-fiber-api/tax"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// calculateOrderTax applies the tax rules of the order's shipping address to the taxable items
+// and records the breakdown on the order. Items carry only their own category; the rules of
+// parent categories apply to them too. Shipping is not taxed: its cost is added afterwards.
+func calculateOrderTax(tx *gorm.DB, order *models.Order, items []tax.Item) error {
+	mode := config.TaxMode()
+
+	var stored []models.TaxRule
+	if order.ShippingAddress.Country != "" {
+		if err := tx.Where("country = ?", order.ShippingAddress.Country).Find(&stored).Error; err != nil {
+			return err
+		}
+	}
+
+	rules := make([]tax.Rule, 0, len(stored))
+	byCategory := false
+	for _, rule := range stored {
+		converted, err := rule.TaxRule()
+		if err != nil {
+			log.Printf("Ignoring tax rule %d with invalid rate %q: %v", rule.ID, rule.Rate, err)
+			continue
+		}
+		rules = append(rules, converted)
+		byCategory = byCategory || rule.CategoryID != nil
+	}
+
+	if byCategory {
+		var categories []models.Category
+		if err := tx.Select("id", "parent_id").Find(&categories).Error; err != nil {
+			return err
+		}
+		for i, item := range items {
+			if len(item.CategoryPath) > 0 {
+				items[i].CategoryPath = models.AncestorIDs(categories, item.CategoryPath[0])
+			}
+		}
+	}
+
+	destination := tax.Destination{Country: order.ShippingAddress.Country, Region: order.ShippingAddress.Region}
+	order.ApplyTax(tax.Calculate(order.Total.Currency, items, destination, rules, mode), mode)
+	return nil
+}
+
+// GetTaxRules - Admin endpoint to list tax rules
+// @Summary      List tax rules
+// @Description  List all tax rules by country. Requires an admin user or an API key with the settings:read scope
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        country  query  string  false  "ISO 3166-1 alpha-2 country code"
+// @Success      200  {array}   models.TaxRule       "Tax rules"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Admin access or settings:read scope required"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/tax-rules [get]
+func GetTaxRules(c *fiber.Ctx) error {
+	query := config.DB.Order("country, region, name, id")
+	if country := c.Query("country"); country != "" {
+		query = query.Where("country = ?", strings.ToUpper(country))
+	}
+
+	rules := []models.TaxRule{}
+	if err := query.Find(&rules).Error; err != nil {
+		return problem.Internal("Failed to fetch tax rules")
+	}
+	return c.JSON(rules)
+}
+
+// CreateTaxRule - Admin endpoint to create a tax rule
+// @Summary      Create tax rule
+// @Description  Create a tax of a percentage on orders shipped to a country, optionally only to one region and only on a category and its subcategories. Of the rules with the same name the most specific one applies; rules with different names add up
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request body models.TaxRuleRequest true "Tax rule data"
+// @Success      201  {object}  models.TaxRule       "Created tax rule"
+// @Failure      400  {object}  models.Problem "Invalid input or category not found"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Admin access or settings:write scope required"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/tax-rules [post]
+func CreateTaxRule(c *fiber.Ctx) error {
+	var req models.TaxRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	rule := models.TaxRule{}
+	if err := applyTaxRuleRequest(&rule, req); err != nil {
+		return err
+	}
+
+	if err := config.DB.Create(&rule).Error; err != nil {
+		return problem.Internal("Failed to create tax rule")
+	}
+
+	log.Printf("Tax rule %d (%s %s%% in %s) created by %s", rule.ID, rule.Name, rule.Rate, rule.Country, actor(c))
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// UpdateTaxRule - Admin endpoint to update a tax rule
+// @Summary      Update tax rule
+// @Description  Update a tax rule. Orders keep the tax they were placed with
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                    true  "Tax rule ID"
+// @Param        request body  models.TaxRuleRequest  true  "Tax rule data"
+// @Success      200  {object}  models.TaxRule       "Updated tax rule"
+// @Failure      400  {object}  models.Problem "Invalid input or category not found"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Unauthorized"
+// @Failure      403  {object}  models.Problem "Admin access or settings:write scope required"
+// @Failure      404  {object}  models.Problem "Tax rule not found"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/tax-rules/{id} [put]
+func UpdateTaxRule(c *fiber.Ctx) error {
+	ruleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid tax rule ID")
+	}
+
+	var rule models.TaxRule
+	if err := config.DB.First(&rule, ruleID).Error; err != nil {
+		return problem.NotFound("tax_rule_not_found", "Tax rule not found")
+	}
+
+	var req models.TaxRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if err := applyTaxRuleRequest(&rule, req); err != nil {
+		return err
+	}
+
+	if err := config.DB.Save(&rule).Error; err != nil {
+		return problem.Internal("Failed to update tax rule")
+	}
+
+	log.Printf("Tax rule %d (%s %s%% in %s) updated by %s", rule.ID, rule.Name, rule.Rate, rule.Country, actor(c))
+	return c.JSON(rule)
+}
+
+// DeleteTaxRule - Admin endpoint to delete a tax rule
+// @Summary      Delete tax rule
+// @Description  Delete a tax rule. Orders keep the tax they were placed with
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Tax rule ID"
+// @Success      200  {object}  models.MessageResponse "Tax rule deleted"
+// @Failure      400  {object}  models.Problem   "Invalid tax rule ID"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      403  {object}  models.Problem   "Admin access or settings:write scope required"
+// @Failure      404  {object}  models.Problem   "Tax rule not found"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Security     APIKey
+// @Router       /api/admin/tax-rules/{id} [delete]
+func DeleteTaxRule(c *fiber.Ctx) error {
+	ruleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid tax rule ID")
+	}
+
+	result := config.DB.Delete(&models.TaxRule{}, ruleID)
+	if result.Error != nil {
+		return problem.Internal("Failed to delete tax rule")
+	}
+	if result.RowsAffected == 0 {
+		return problem.NotFound("tax_rule_not_found", "Tax rule not found")
+	}
+
+	log.Printf("Tax rule %d deleted by %s", ruleID, actor(c))
+	return c.JSON(models.MessageResponse{Message: "Tax rule deleted successfully"})
+}
+
+// applyTaxRuleRequest validates a create/update request and copies it onto the rule,
+// or returns the problem with the request
+func applyTaxRuleRequest(rule *models.TaxRule, req models.TaxRuleRequest) *problem.Error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Country = strings.ToUpper(strings.TrimSpace(req.Country))
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	rate, err := tax.ParseRate(req.Rate)
+	if err != nil {
+		return problem.Validation([]models.FieldError{{Field: "rate", Rule: "percentage", Message: "must be a decimal percentage between 0 and 100"}})
+	}
+
+	if req.CategoryID != nil {
+		var category models.Category
+		if err := config.DB.First(&category, *req.CategoryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return problem.BadRequest("category_not_found", "Category not found")
+			}
+			return problem.Internal("Failed to fetch categories")
+		}
+	}
+
+	rule.Name = req.Name
+	rule.Country = req.Country
+	rule.Region = strings.TrimSpace(req.Region)
+	rule.CategoryID = req.CategoryID
+	rule.Rate = tax.FormatRate(rate)
+	return nil
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var errInvalidUserToken = errors.New("invalid or expired token")
+
+// issueUserToken creates a single-use token for the given purpose. The returned
+// token is a random value signed with the token secret; only its hash is stored.
+func issueUserToken(db *gorm.DB, userID uint, purpose, data string, ttl time.Duration) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(random)
+
+	token := models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashUserToken(value),
+		Data:      data,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := db.Create(&token).Error; err != nil {
+		return "", err
+	}
+
+	return value + "." + signUserToken(purpose, value), nil
+}
+
+// consumeUserToken verifies a token's signature, looks it up and marks it as used.
+// Expired, used, unknown or tampered tokens all return errInvalidUserToken.
+func consumeUserToken(db *gorm.DB, raw, purpose string) (*models.UserToken, error) {
+	value, signature, ok := strings.Cut(raw, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signUserToken(purpose, value))) {
+		return nil, errInvalidUserToken
+	}
+
+	var token models.UserToken
+	err := db.Where("token_hash = ? AND purpose = ?", hashUserToken(value), purpose).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errInvalidUserToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, errInvalidUserToken
+	}
+
+	// Guard against the token being redeemed twice concurrently
+	now := time.Now()
+	result := db.Model(&models.UserToken{}).Where("id = ? AND used_at IS NULL", token.ID).Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errInvalidUserToken
+	}
+	token.UsedAt = &now
+
+	return &token, nil
+}
+
+// revokeUserTokens makes the user's outstanding tokens for any of the purposes unusable
+func revokeUserTokens(tx *gorm.DB, userID uint, purposes ...string) error {
+	return tx.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose IN ? AND used_at IS NULL", userID, purposes).
+		Update("used_at", time.Now()).Error
+}
+
+func signUserToken(purpose, value string) string {
+	mac := hmac.New(sha256.New, config.TokenSecret())
+	mac.Write([]byte(purpose + "." + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func hashUserToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"go-fiber-api/tokens"
+	"go-fiber-api/totp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	recoveryCodeCount = 10
+	mfaTokenTTL       = 5 * time.Minute
+	mfaTokenPurpose   = "mfa"
+)
+
+// SetupTwoFactor - Protected endpoint to start two-factor enrollment
+// @Summary      Start two-factor setup
+// @Description  Generate a new TOTP secret and return it as an otpauth URI and a base64-encoded QR code PNG. Two-factor authentication is enabled once a code is confirmed
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.TwoFactorSetupResponse "Enrollment details"
+// @Failure      401  {object}  models.Problem          "Unauthorized"
+// @Failure      404  {object}  models.Problem          "User not found"
+// @Failure      409  {object}  models.Problem          "Two-factor authentication already enabled"
+// @Failure      500  {object}  models.Problem          "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/2fa/setup [post]
+func SetupTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	user, err := config.Users.FindByID(userID)
+	if err != nil {
+		return problem.NotFound("user_not_found", "User not found")
+	}
+	if user.TwoFactorEnabled {
+		return problem.Conflict("two_factor_already_enabled", "Two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return problem.Internal("Could not generate secret")
+	}
+
+	uri := totp.URI(config.TOTPIssuer(), user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return problem.Internal("Could not generate QR code")
+	}
+
+	// The secret stays pending until a code generated from it is confirmed
+	if err := config.Users.Update(user.ID, map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	}); err != nil {
+		return problem.Internal("Could not start two-factor setup")
+	}
+
+	return c.JSON(models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCodePNG:  base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// ConfirmTwoFactor - Protected endpoint to finish two-factor enrollment
+// @Summary      Confirm two-factor setup
+// @Description  Enable two-factor authentication with a code from the authenticator app. Returns one-time recovery codes, which are only shown once
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        request body models.TwoFactorCodeRequest true "Authenticator code"
+// @Success      200  {object}  models.RecoveryCodesResponse "Two-factor enabled, recovery codes"
+// @Failure      400  {object}  models.Problem         "Invalid input or setup not started"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem         "Unauthorized or invalid code"
+// @Failure      404  {object}  models.Problem         "User not found"
+// @Failure      409  {object}  models.Problem         "Two-factor authentication already enabled"
+// @Failure      429  {object}  models.Problem         "Too many failed attempts, see Retry-After"
+// @Failure      500  {object}  models.Problem         "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/2fa/confirm [post]
+func ConfirmTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	user, err := config.Users.FindByID(userID)
+	if err != nil {
+		return problem.NotFound("user_not_found", "User not found")
+	}
+	if user.TwoFactorEnabled {
+		return problem.Conflict("two_factor_already_enabled", "Two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return problem.BadRequest("two_factor_setup_not_started", "Two-factor setup has not been started")
+	}
+
+	// Codes are only six digits, so failures count towards the account's login lockout
+	throttleKeys := loginThrottleKeys(c, user.Email)
+	if wait := loginLockedFor(throttleKeys); wait > 0 {
+		return tooManyLoginAttempts(c, wait)
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		recordLoginFailure(throttleKeys)
+		return problem.Unauthorized("invalid_authentication_code", "Invalid authentication code")
+	}
+
+	var codes []string
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		if err := config.Users.WithTx(tx).Update(user.ID, map[string]interface{}{
+			"two_factor_enabled": true,
+			"totp_last_step":     step,
+		}); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return problem.Internal("Could not enable two-factor authentication")
+	}
+
+	return c.JSON(models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor - Protected endpoint to turn off two-factor authentication
+// @Summary      Disable two-factor authentication
+// @Description  Turn off two-factor authentication after confirming the current password and an authenticator code that was not used before. Failed attempts count towards the login lockout
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Param        request body models.DisableTwoFactorRequest true "Current password and authenticator code"
+// @Success      200  {object}  models.MessageResponse "Two-factor disabled"
+// @Failure      400  {object}  models.Problem   "Invalid input or two-factor not enabled"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem   "Unauthorized, wrong current password or invalid code"
+// @Failure      404  {object}  models.Problem   "User not found"
+// @Failure      429  {object}  models.Problem   "Too many failed attempts, see Retry-After"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/2fa [delete]
+func DisableTwoFactor(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.DisableTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	user, err := config.Users.FindByID(userID)
+	if err != nil {
+		return problem.NotFound("user_not_found", "User not found")
+	}
+	if !user.TwoFactorEnabled {
+		return problem.BadRequest("two_factor_not_enabled", "Two-factor authentication is not enabled")
+	}
+
+	// A stolen session alone must not be enough to remove the second factor
+	throttleKeys := loginThrottleKeys(c, user.Email)
+	if wait := loginLockedFor(throttleKeys); wait > 0 {
+		return tooManyLoginAttempts(c, wait)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		recordLoginFailure(throttleKeys)
+		return problem.Unauthorized("current_password_incorrect", "Current password is incorrect")
+	}
+	verified, err := useTOTPCode(user, req.Code)
+	if err != nil {
+		return problem.Internal("Could not verify code")
+	}
+	if !verified {
+		recordLoginFailure(throttleKeys)
+		return problem.Unauthorized("invalid_authentication_code", "Invalid authentication code")
+	}
+
+	err = config.Tx.Transaction(func(tx *gorm.DB) error {
+		if err := config.Users.WithTx(tx).Update(user.ID, map[string]interface{}{
+			"two_factor_enabled": false,
+			"totp_secret":        "",
+			"totp_last_step":     0,
+		}); err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		return problem.Internal("Could not disable two-factor authentication")
+	}
+
+	return c.JSON(models.MessageResponse{Message: "Two-factor authentication disabled"})
+}
+
+// LoginTwoFactor completes a login for users with two-factor authentication
+// @Summary      Complete two-factor login
+// @Description  Exchange the MFA challenge token returned by login and an authenticator or recovery code for an access token
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body models.MFALoginRequest true "Challenge token and code"
+// @Success      200  {object}  models.TokenResponse "Login successful with token"
+// @Failure      400  {object}  models.Problem "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem "Invalid or expired challenge, or invalid code"
+// @Failure      429  {object}  models.Problem "Too many failed attempts, see Retry-After"
+// @Failure      500  {object}  models.Problem "Internal server error"
+// @Router       /auth/login/2fa [post]
+func LoginTwoFactor(c *fiber.Ctx) error {
+	var req models.MFALoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	userID, err := parseMFAToken(req.MFAToken)
+	if err != nil {
+		return problem.Unauthorized("invalid_mfa_token", "Invalid or expired MFA token")
+	}
+
+	user, err := config.Users.FindByID(userID)
+	if err != nil || !user.TwoFactorEnabled {
+		return problem.Unauthorized("invalid_mfa_token", "Invalid or expired MFA token")
+	}
+
+	throttleKeys := loginThrottleKeys(c, user.Email)
+	if wait := loginLockedFor(throttleKeys); wait > 0 {
+		return tooManyLoginAttempts(c, wait)
+	}
+
+	var verified bool
+	if req.Code != "" {
+		verified, err = useTOTPCode(user, req.Code)
+	} else {
+		verified, err = useRecoveryCode(user.ID, req.RecoveryCode)
+	}
+	if err != nil {
+		return problem.Internal("Could not verify code")
+	}
+	if !verified {
+		recordLoginFailure(throttleKeys)
+		return problem.Unauthorized("invalid_authentication_code", "Invalid authentication code")
+	}
+	resetLoginFailures(user.Email)
+
+	tokenString, err := generateToken(c, *user)
+	if err != nil {
+		return problem.Internal("Could not generate token")
+	}
+
+	return c.JSON(models.TokenResponse{Token: tokenString})
+}
+
+// generateMFAToken issues a short-lived token proving the password step of a login succeeded.
+// Its purpose claim keeps it from being accepted as an access token.
+func generateMFAToken(user models.User) (string, error) {
+	jti, err := tokens.NewID()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	return signToken(user, mfaTokenPurpose, jti, now, now.Add(mfaTokenTTL))
+}
+
+func parseMFAToken(tokenString string) (uint, error) {
+	claims, err := config.Keys.Verify(tokenString, config.TokenValidation(mfaTokenPurpose))
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID()
+}
+
+// useTOTPCode validates an authenticator code, refusing codes from steps that were already used
+func useTOTPCode(user *models.User, code string) (bool, error) {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return false, nil
+	}
+
+	return config.Users.AdvanceTOTPStep(user.ID, step)
+}
+
+// useRecoveryCode redeems one of the user's unused recovery codes
+func useRecoveryCode(userID uint, code string) (bool, error) {
+	result := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// replaceRecoveryCodes discards the user's recovery codes and generates a new set
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		random := make([]byte, 7)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		value := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(random)[:10]
+		code := value[:5] + "-" + value[5:]
+
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode normalizes a recovery code so formatting differences do not matter, then hashes it
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-api/models"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate evaluates the validate struct tags of request payloads
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// Report fields by their JSON names, which is what clients send
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterValidation("api_key_scope", func(fl validator.FieldLevel) bool {
+		return models.ValidAPIKeyScope(fl.Field().String())
+	})
+	return v
+}
+
+// validateStruct checks req against its validate tags and returns the failed fields, or nil if it is valid
+func validateStruct(req interface{}) []models.FieldError {
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []models.FieldError{{Field: "", Rule: "invalid", Message: err.Error()}}
+	}
+
+	fields := make([]models.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		// Drop the struct name so nested fields read like items[0].quantity
+		field := fe.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		fields = append(fields, models.FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Message: validationMessage(fe),
+		})
+	}
+	return fields
+}
+
+func validationMessage(fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required when " + snakeCase(fe.Param()) + " is not given"
+	case "excluded_with":
+		return "must not be given together with " + snakeCase(fe.Param())
+	case "required_if":
+		field, value, _ := strings.Cut(fe.Param(), " ")
+		return "is required when " + snakeCase(field) + " is " + value
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if isString {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s entries", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if isString {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	case "len":
+		if isString {
+			return fmt.Sprintf("must be exactly %s characters long", fe.Param())
+		}
+		return "must have length " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "numeric":
+		return "must contain only digits"
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code"
+	case "api_key_scope":
+		return "must be one of " + strings.Join(models.APIKeyScopes, ", ")
+	default:
+		return "is invalid"
+	}
+}
+
+// snakeCase converts a Go field name such as RecoveryCode to its JSON name recovery_code
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
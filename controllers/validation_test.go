@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"go-fiber-api/models"
+	"testing"
+)
+
+func TestValidateStructReportsFieldsByJSONName(t *testing.T) {
+	fields := validateStruct(RegisterRequest{Email: "not-an-email", Password: "x", FirstName: "Jane"})
+
+	expected := map[string]string{
+		"email":     "email",
+		"password":  "min",
+		"last_name": "required",
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("Expected %d field errors, got %+v", len(expected), fields)
+	}
+	for _, field := range fields {
+		if expected[field.Field] != field.Rule {
+			t.Errorf("Unexpected field error %+v", field)
+		}
+	}
+
+	if fields := validateStruct(RegisterRequest{Email: "jane@example.com", Password: "secret1", FirstName: "Jane", LastName: "Doe"}); fields != nil {
+		t.Errorf("Expected valid request, got %+v", fields)
+	}
+}
+
+func TestValidateStructNestedAndConditionalRules(t *testing.T) {
+	fields := validateStruct(models.CreateOrderRequest{
+		Items: []models.CreateOrderItemRequest{{ProductID: 1, Quantity: 0}},
+	})
+	if len(fields) != 1 || fields[0].Field != "items[0].quantity" {
+		t.Errorf("Expected items[0].quantity to fail, got %+v", fields)
+	}
+
+	fields = validateStruct(models.CreateOrderRequest{})
+	if len(fields) != 1 || fields[0].Field != "total" || fields[0].Message != "is required when items is not given" {
+		t.Errorf("Expected total to be required without items, got %+v", fields)
+	}
+
+	fields = validateStruct(models.CreateAPIKeyRequest{Name: "export", Scopes: []string{"orders:read", "orders:delete"}})
+	if len(fields) != 1 || fields[0].Field != "scopes[1]" || fields[0].Rule != "api_key_scope" {
+		t.Errorf("Expected unknown scope to fail, got %+v", fields)
+	}
+}
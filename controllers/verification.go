@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-fiber-api/config"
+	"go-fiber-api/mailer"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const emailVerificationTTL = 24 * time.Hour
+
+// sendVerificationEmail mails the user a link that confirms ownership of their email address
+func sendVerificationEmail(ctx context.Context, user models.User) error {
+	token, err := issueUserToken(config.DB, user.ID, models.TokenPurposeEmailVerification, "", emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := config.AppBaseURL() + "/auth/verify?token=" + url.QueryEscape(token)
+	return config.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %d hours.\n",
+			user.FirstName, link, int(emailVerificationTTL.Hours())),
+	})
+}
+
+// VerifyEmail confirms a user's email address
+// @Summary      Verify email address
+// @Description  Confirm ownership of an email address with the single-use token sent by email. Tokens sent for an email change switch the account to the new address
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        token query string true "Verification token"
+// @Success      200  {object}  models.MessageResponse "Email verified"
+// @Failure      400  {object}  models.Problem   "Invalid or expired token"
+// @Failure      409  {object}  models.Problem   "New email already in use"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Router       /auth/verify [get]
+func VerifyEmail(c *fiber.Ctx) error {
+	raw := c.Query("token")
+	if raw == "" {
+		return problem.BadRequest("verification_token_missing", "Verification token is required")
+	}
+
+	err := config.Tx.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeUserToken(tx, raw, models.TokenPurposeEmailVerification)
+		if err == nil {
+			return config.Users.WithTx(tx).MarkEmailVerified(token.UserID, time.Now())
+		}
+		if !errors.Is(err, errInvalidUserToken) {
+			return err
+		}
+
+		// Links sent by an email change confirm the new address the same way
+		token, err = consumeUserToken(tx, raw, models.TokenPurposeEmailChange)
+		if err != nil {
+			return err
+		}
+		return applyEmailChange(tx, token)
+	})
+	if errors.Is(err, errInvalidUserToken) {
+		return problem.BadRequest("invalid_verification_token", "Invalid or expired verification token")
+	}
+	if errors.Is(err, errEmailInUse) {
+		return problem.Conflict("email_taken", "User with this email already exists")
+	}
+	if err != nil {
+		return problem.Internal("Could not verify email")
+	}
+
+	return c.JSON(models.MessageResponse{Message: "Email verified successfully"})
+}
+
+// ResendVerificationEmail sends a new verification link to the authenticated user
+// @Summary      Resend verification email
+// @Description  Send a new email verification link to the authenticated user's address
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Success      202  {object}  models.MessageResponse "Verification email sent"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      404  {object}  models.Problem   "User not found"
+// @Failure      409  {object}  models.Problem   "Email already verified"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Router       /api/profile/verify-email [post]
+func ResendVerificationEmail(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	user, err := config.Users.FindByID(userID)
+	if err != nil {
+		return problem.NotFound("user_not_found", "User not found")
+	}
+	if user.IsEmailVerified() {
+		return problem.Conflict("email_already_verified", "Email address is already verified")
+	}
+
+	if err := sendVerificationEmail(c.Context(), *user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+		return problem.Internal("Could not send verification email")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.MessageResponse{Message: "Verification email sent"})
+}
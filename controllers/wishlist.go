@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetWishlist - Protected endpoint to list the user's wishlist
+// @Summary      Get wishlist
+// @Description  Retrieve the authenticated user's wishlist, flagging items that are out of stock, back in stock, or cheaper than when saved. Products removed from the catalog are flagged unavailable
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   models.WishlistItemResponse "Wishlist items"
+// @Failure      401  {object}  models.Problem        "Unauthorized"
+// @Failure      500  {object}  models.Problem        "Internal server error"
+// @Security     Bearer
+// @Router       /api/wishlist [get]
+func GetWishlist(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var items []models.WishlistItem
+	// Load removed products too, so their entries are flagged instead of showing an empty product
+	if err := config.DB.Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Product.Category").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&items).Error; err != nil {
+		return problem.Internal("Failed to fetch wishlist")
+	}
+
+	response := make([]models.WishlistItemResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, models.NewWishlistItemResponse(item))
+	}
+	return c.JSON(response)
+}
+
+// AddWishlistItem - Protected endpoint to save a product to the wishlist
+// @Summary      Add product to wishlist
+// @Description  Save a product to the authenticated user's wishlist, remembering its current price and stock state
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Param        request body models.AddWishlistItemRequest true "Product to save"
+// @Success      201  {object}  models.WishlistItemResponse "Saved wishlist item"
+// @Failure      400  {object}  models.Problem        "Invalid input"
+// @Failure      422  {object}  models.Problem "Validation failed"
+// @Failure      401  {object}  models.Problem        "Unauthorized"
+// @Failure      404  {object}  models.Problem        "Product not found"
+// @Failure      409  {object}  models.Problem        "Product already in wishlist"
+// @Failure      500  {object}  models.Problem        "Internal server error"
+// @Security     Bearer
+// @Router       /api/wishlist [post]
+func AddWishlistItem(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req models.AddWishlistItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.BadRequest(problem.CodeInvalidInput, "Invalid input")
+	}
+
+	if fields := validateStruct(req); fields != nil {
+		return problem.Validation(fields)
+	}
+
+	product, err := config.Products.FindByID(req.ProductID)
+	if err != nil {
+		return problem.NotFound("product_not_found", "Product not found")
+	}
+
+	var existing int64
+	config.DB.Model(&models.WishlistItem{}).Where("user_id = ? AND product_id = ?", userID, product.ID).Count(&existing)
+	if existing > 0 {
+		return problem.Conflict("already_in_wishlist", "Product is already in your wishlist")
+	}
+
+	item := models.WishlistItem{
+		UserID:           userID,
+		ProductID:        product.ID,
+		PriceWhenAdded:   product.Price,
+		InStockWhenAdded: product.Stock > 0,
+	}
+	if err := config.DB.Create(&item).Error; err != nil {
+		return problem.Internal("Failed to add product to wishlist")
+	}
+
+	item.Product = *product
+	return c.Status(fiber.StatusCreated).JSON(models.NewWishlistItemResponse(item))
+}
+
+// RemoveWishlistItem - Protected endpoint to remove a product from the wishlist
+// @Summary      Remove product from wishlist
+// @Description  Remove a product from the authenticated user's wishlist
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Param        productId path int true "Product ID"
+// @Success      200  {object}  models.MessageResponse "Product removed from wishlist"
+// @Failure      400  {object}  models.Problem   "Invalid product ID"
+// @Failure      401  {object}  models.Problem   "Unauthorized"
+// @Failure      404  {object}  models.Problem   "Product not in wishlist"
+// @Failure      500  {object}  models.Problem   "Internal server error"
+// @Security     Bearer
+// @Router       /api/wishlist/{productId} [delete]
+func RemoveWishlistItem(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	productID, err := strconv.Atoi(c.Params("productId"))
+	if err != nil {
+		return problem.BadRequest(problem.CodeInvalidID, "Invalid product ID")
+	}
+
+	result := config.DB.Where("user_id = ? AND product_id = ?", userID, productID).Delete(&models.WishlistItem{})
+	if result.Error != nil {
+		return problem.Internal("Failed to remove product from wishlist")
+	}
+	if result.RowsAffected == 0 {
+		return problem.NotFound("not_in_wishlist", "Product is not in your wishlist")
+	}
+
+	return c.JSON(models.MessageResponse{
+		Message: "Product removed from wishlist",
+	})
+}
@@ -0,0 +1,91 @@
+// Package images validates uploaded images and generates thumbnails.
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif" // register the GIF decoder for image.Decode
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+// ErrUnsupportedType is returned when uploaded data is not a supported image format
+var ErrUnsupportedType = errors.New("images: unsupported image type")
+
+// ErrTooManyPixels is returned when an image's dimensions exceed the pixel limit. Small files
+// can declare huge dimensions, and decoding allocates memory for every pixel.
+var ErrTooManyPixels = errors.New("images: image dimensions too large")
+
+// allowedTypes maps the supported content types to the file extension used when storing them
+var allowedTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Sniff detects the content type from the data itself rather than trusting the
+// client-supplied header, returning the type and the file extension to store it with
+func Sniff(data []byte) (string, string, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedTypes[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedType
+	}
+	return contentType, ext, nil
+}
+
+// Thumbnail decodes an image and scales it down so that neither side exceeds maxSide,
+// keeping the aspect ratio. Images of more than maxPixels pixels are rejected with
+// ErrTooManyPixels before they are decoded. PNG and GIF sources produce PNG thumbnails to keep
+// transparency; everything else is encoded as JPEG.
+func Thumbnail(data []byte, maxSide int, maxPixels int64) (thumb []byte, contentType string, width int, height int, err error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, "", 0, 0, ErrTooManyPixels
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+
+	bounds := src.Bounds()
+	width, height = bounds.Dx(), bounds.Dy()
+	tw, th := width, height
+	if tw > maxSide || th > maxSide {
+		if tw >= th {
+			th, tw = th*maxSide/tw, maxSide
+		} else {
+			tw, th = tw*maxSide/th, maxSide
+		}
+		if tw < 1 {
+			tw = 1
+		}
+		if th < 1 {
+			th = 1
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if format == "png" || format == "gif" {
+		err = png.Encode(&buf, dst)
+		contentType = "image/png"
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		contentType = "image/jpeg"
+	}
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+	return buf.Bytes(), contentType, width, height, nil
+}
@@ -0,0 +1,38 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestThumbnailScalesDown(t *testing.T) {
+	thumb, contentType, width, height, err := Thumbnail(encodePNG(t, 600, 300), 100, 1_000_000)
+	if err != nil {
+		t.Fatalf("Thumbnail returned error: %v", err)
+	}
+	if contentType != "image/png" || width != 600 || height != 300 {
+		t.Errorf("Expected a PNG thumbnail of a 600x300 image, got %s %dx%d", contentType, width, height)
+	}
+	cfg, _, _ := image.DecodeConfig(bytes.NewReader(thumb))
+	if cfg.Width != 100 || cfg.Height != 50 {
+		t.Errorf("Expected a 100x50 thumbnail, got %dx%d", cfg.Width, cfg.Height)
+	}
+}
+
+func TestThumbnailRejectsTooManyPixels(t *testing.T) {
+	if _, _, _, _, err := Thumbnail(encodePNG(t, 300, 200), 100, 300*200-1); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Expected ErrTooManyPixels, got %v", err)
+	}
+}
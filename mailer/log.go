@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogMailer is a development mailer that appends messages to a file, or writes
+// them to the application log when no path is set, instead of delivering them
+type LogMailer struct {
+	Path string
+	From string
+
+	mu sync.Mutex
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	data := format(m.From, msg, time.Now())
+
+	if m.Path == "" {
+		log.Printf("Mail to %s:\n%s", msg.To, data)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, []byte("\r\n")...)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,131 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts a single SMTP session and records the envelope and message data
+type fakeSMTPServer struct {
+	listener net.Listener
+	from     string
+	to       []string
+	data     string
+	done     chan struct{}
+}
+
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start fake SMTP server: %v", err)
+	}
+
+	server := &fakeSMTPServer{listener: listener, done: make(chan struct{})}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost fake SMTP ready")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	m := &SMTPMailer{Host: host, Port: portNumber, From: "shop@example.com"}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := m.Send(ctx, Message{
+		To:      "customer@example.com",
+		Subject: "Verify your email",
+		Body:    "Open this link:\nhttp://localhost:3000/auth/verify?token=abc",
+	})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	<-server.done
+
+	if server.from != "shop@example.com" {
+		t.Errorf("Expected sender shop@example.com, got %q", server.from)
+	}
+	if len(server.to) != 1 || server.to[0] != "customer@example.com" {
+		t.Errorf("Expected recipient customer@example.com, got %v", server.to)
+	}
+	if !strings.Contains(server.data, "Subject: Verify your email\r\n") {
+		t.Errorf("Message is missing the subject header:\n%s", server.data)
+	}
+	if !strings.Contains(server.data, "\r\nhttp://localhost:3000/auth/verify?token=abc\r\n") {
+		t.Errorf("Message is missing the body:\n%s", server.data)
+	}
+}
+
+func TestSMTPMailerRejectsHeaderInjection(t *testing.T) {
+	m := &SMTPMailer{Host: "127.0.0.1", Port: 1, From: "shop@example.com"}
+
+	err := m.Send(context.Background(), Message{
+		To:      "customer@example.com\r\nBcc: attacker@example.com",
+		Subject: "Hello",
+	})
+	if err == nil {
+		t.Fatal("Expected an error for a recipient containing a line break")
+	}
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"go-fiber-api/config"
+	"go-fiber-api/problem"
 	"go-fiber-api/routes"
+	"go-fiber-api/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 
 	_ "go-fiber-api/docs" // Generated swagger docs
@@ -31,13 +35,39 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.apikey APIKey
+// @in header
+// @name X-API-Key
+// @description API key created by an admin; accepted on admin endpoints according to its scopes.
+
 func main() {
-	app := fiber.New()
+	config.ConnectStorage()
+	config.LoadSigningKeys()
+
+	proxyHeader, trustedProxies := config.ProxySettings()
+	app := fiber.New(fiber.Config{
+		// Leave room for multipart overhead on top of the largest accepted image
+		BodyLimit: int(config.MaxImageSize) + 1<<20,
+		// Client IPs key login lockouts, so forwarded addresses are only believed from trusted proxies
+		ProxyHeader:             proxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          trustedProxies,
+		// Handlers return typed errors; this renders them as problem+json
+		ErrorHandler: problem.ErrorHandler,
+	})
+
+	// Tag every request with an ID that error responses echo back
+	app.Use(requestid.New(requestid.Config{ContextKey: problem.RequestIDKey}))
+
+	// Turn panics, such as adding up amounts of different currencies, into 500 responses
+	app.Use(recover.New())
 
 	// Enable CORS
 	app.Use(cors.New())
 
 	config.ConnectDatabase()
+	config.ConnectMailer()
+	config.LoadOIDCProviders()
 
 	// Seed test data for API testing
 	config.SeedTestData()
@@ -45,6 +75,11 @@ func main() {
 	// Setup API routes
 	routes.SetupRoutes(app)
 
+	// Serve uploaded files when they are stored on the local filesystem
+	if local, ok := config.Storage.(*storage.LocalStorage); ok {
+		app.Static(local.BaseURL, local.Root)
+	}
+
 	// Swagger endpoint
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminOnly restricts a route to users with the admin role; API keys are refused.
+// It must run after AuthMiddleware, which provides the user ID.
+func AdminOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("apiKey").(*models.APIKey); ok {
+			return problem.Forbidden("api_key_not_allowed", "Admin user required, API keys are not accepted")
+		}
+
+		userID, ok := c.Locals("userID").(uint)
+		if !ok {
+			return problem.Unauthorized("authentication_required", "Authentication required")
+		}
+
+		user, err := config.Users.FindByID(userID)
+		if err != nil {
+			return problem.Unauthorized("user_not_found", "User not found")
+		}
+
+		if user.Role != "admin" {
+			return problem.Forbidden("admin_required", "Admin access required")
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHeader is the request header carrying an API key
+const APIKeyHeader = "X-API-Key"
+
+// lastUsedResolution limits how often the last-used timestamp of a busy key is written
+const lastUsedResolution = time.Minute
+
+// AuthOrAPIKeyMiddleware accepts an API key in the X-API-Key header as well as a Bearer token.
+// API key requests have no user, so every route behind it must be guarded by RequireScope.
+func AuthOrAPIKeyMiddleware() fiber.Handler {
+	bearer := AuthMiddleware()
+	return func(c *fiber.Ctx) error {
+		raw := c.Get(APIKeyHeader)
+		if raw == "" {
+			return bearer(c)
+		}
+
+		key, ok := lookupAPIKey(raw)
+		if !ok {
+			return problem.Unauthorized("invalid_api_key", "Invalid API key")
+		}
+
+		now := time.Now()
+		if key.Expired(now) {
+			return problem.Unauthorized("api_key_expired", "API key has expired")
+		}
+		if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+			config.DB.Model(key).UpdateColumn("last_used_at", now)
+		}
+
+		c.Locals("apiKey", key)
+		return c.Next()
+	}
+}
+
+func lookupAPIKey(raw string) (*models.APIKey, bool) {
+	prefix, ok := models.ParseAPIKeyPrefix(raw)
+	if !ok {
+		return nil, false
+	}
+
+	var key models.APIKey
+	if err := config.DB.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return nil, false
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(models.HashAPIKey(raw))) != 1 {
+		return nil, false
+	}
+	return &key, true
+}
+
+// RequireScope allows API keys holding scope and admin users.
+// It must run after AuthOrAPIKeyMiddleware.
+func RequireScope(scope string) fiber.Handler {
+	adminOnly := AdminOnly()
+	return func(c *fiber.Ctx) error {
+		key, ok := c.Locals("apiKey").(*models.APIKey)
+		if !ok {
+			return adminOnly(c)
+		}
+
+		if !key.Scopes.Has(scope) {
+			return problem.Forbidden("insufficient_scope", "API key is missing the "+scope+" scope")
+		}
+		return c.Next()
+	}
+}
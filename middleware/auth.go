@@ -1,71 +1,82 @@
 package middleware
 
 import (
-	"os"
-	"strconv"
+	"go-fiber-api/config"
+	"go-fiber-api/models"
+	"go-fiber-api/problem"
+	"go-fiber-api/tokens"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
 )
 
+// lastSeenResolution limits how often the last-seen timestamp of a session is written
+const lastSeenResolution = time.Minute
+
 func AuthMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Authorization header is required",
-			})
+			return problem.Unauthorized("authorization_missing", "Authorization header is required")
 		}
 
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Bearer token is required",
-			})
+			return problem.Unauthorized("bearer_token_missing", "Bearer token is required")
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Bearer token is required",
-			})
+			return problem.Unauthorized("bearer_token_missing", "Bearer token is required")
 		}
 
-		// Get JWT secret from environment or use default
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "your-secret-key"
+		claims, err := config.Keys.Verify(tokenString, config.TokenValidation(""))
+		if err != nil {
+			return tokenProblem(err)
 		}
 
-		claims := &jwt.MapClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token",
-			})
+		userID, err := claims.UserID()
+		if err != nil {
+			return problem.Unauthorized("malformed_token", "Invalid user ID in token")
 		}
 
-		// Extract user ID from claims
-		if userIDFloat, ok := (*claims)["user_id"].(float64); ok {
-			userID := uint(userIDFloat)
-			c.Locals("userID", userID)
-		} else if userIDStr, ok := (*claims)["user_id"].(string); ok {
-			userID, err := strconv.ParseUint(userIDStr, 10, 32)
-			if err != nil {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Invalid user ID in token",
-				})
-			}
-			c.Locals("userID", uint(userID))
-		} else {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "User ID not found in token",
+		// Tokens are only accepted while their session is active, so signed-out devices lose access
+		var session models.Session
+		if err := config.DB.Where("token_id = ? AND user_id = ?", claims.ID, userID).First(&session).Error; err != nil {
+			return problem.Unauthorized("session_not_found", "Session not found")
+		}
+		now := time.Now()
+		if !session.Active(now) {
+			return problem.Unauthorized("session_revoked", "Session has been revoked")
+		}
+		if now.Sub(session.LastSeenAt) >= lastSeenResolution {
+			config.DB.Model(&session).UpdateColumns(map[string]interface{}{
+				"last_seen_at": now,
+				"ip_address":   c.IP(),
 			})
 		}
 
+		c.Locals("userID", userID)
+		c.Locals("sessionID", session.ID)
+		c.Locals("claims", claims)
 		return c.Next()
 	}
 }
+
+// tokenProblem tells clients why a token was rejected, e.g. so they know to log in again after expiry
+func tokenProblem(err error) *problem.Error {
+	switch err {
+	case tokens.ErrTokenExpired:
+		return problem.Unauthorized("token_expired", "Token has expired")
+	case tokens.ErrTokenNotYetValid:
+		return problem.Unauthorized("token_not_yet_valid", "Token is not valid yet")
+	case tokens.ErrTokenSignatureInvalid:
+		return problem.Unauthorized("invalid_token_signature", "Invalid token signature")
+	case tokens.ErrInvalidIssuer, tokens.ErrInvalidAudience:
+		return problem.Unauthorized("foreign_token", "Token was not issued for this API")
+	case tokens.ErrWrongPurpose:
+		return problem.Unauthorized("wrong_token_purpose", "Token cannot be used for API access")
+	default:
+		return problem.Unauthorized("malformed_token", "Malformed token")
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Address is a postal address. Orders embed a copy, so editing or deleting an address book
+// entry does not change where past orders went.
+// @Description Postal address
+type Address struct {
+	Name       string `json:"name" gorm:"not null;default:''" example:"John Doe"`
+	Line1      string `json:"line1" gorm:"not null;default:''" example:"Marienplatz 8"`
+	Line2      string `json:"line2" gorm:"not null;default:''" example:""`
+	City       string `json:"city" gorm:"not null;default:''" example:"Munich"`
+	Region     string `json:"region" gorm:"not null;default:''" example:"BY"`
+	PostalCode string `json:"postal_code" gorm:"not null;default:''" example:"80331"`
+	Country    string `json:"country" gorm:"type:varchar(2);not null;default:''" example:"DE"`
+	Phone      string `json:"phone" gorm:"not null;default:''" example:"+49 89 123456"`
+}
+
+// IsZero reports whether no address is set
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// UserAddress is an entry of a user's address book
+// @Description Address book entry
+type UserAddress struct {
+	ID     uint `json:"id" gorm:"primaryKey" example:"1"`
+	UserID uint `json:"-" gorm:"not null;index"`
+	Address
+	DefaultShipping bool      `json:"default_shipping" gorm:"not null;default:false" example:"true"`
+	DefaultBilling  bool      `json:"default_billing" gorm:"not null;default:false" example:"true"`
+	CreatedAt       time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt       time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// AddressRequest represents the payload for adding or updating an address book entry
+// @Description Address create/update request payload
+type AddressRequest struct {
+	Name            string `json:"name" validate:"required,max=100" example:"John Doe"`
+	Line1           string `json:"line1" validate:"required,max=200" example:"Marienplatz 8"`
+	Line2           string `json:"line2" validate:"max=200" example:""`
+	City            string `json:"city" validate:"required,max=100" example:"Munich"`
+	Region          string `json:"region" validate:"max=100" example:"BY"`
+	PostalCode      string `json:"postal_code" validate:"max=20" example:"80331"`
+	Country         string `json:"country" validate:"required,iso3166_1_alpha2" example:"DE"`
+	Phone           string `json:"phone" validate:"max=30" example:"+49 89 123456"`
+	DefaultShipping bool   `json:"default_shipping" example:"true"`
+	DefaultBilling  bool   `json:"default_billing" example:"true"`
+}
+
+// Normalize trims the fields and upper-cases the country code, before validation
+func (r *AddressRequest) Normalize() {
+	for _, field := range []*string{&r.Name, &r.Line1, &r.Line2, &r.City, &r.Region, &r.PostalCode, &r.Phone} {
+		*field = strings.TrimSpace(*field)
+	}
+	r.Country = strings.ToUpper(strings.TrimSpace(r.Country))
+}
+
+// Apply copies the request onto the address book entry. Default flags are only ever set here;
+// clearing them on the user's other entries is up to the caller.
+func (r AddressRequest) Apply(address *UserAddress) {
+	address.Address = Address{
+		Name:       r.Name,
+		Line1:      r.Line1,
+		Line2:      r.Line2,
+		City:       r.City,
+		Region:     r.Region,
+		PostalCode: r.PostalCode,
+		Country:    r.Country,
+		Phone:      r.Phone,
+	}
+	address.DefaultShipping = address.DefaultShipping || r.DefaultShipping
+	address.DefaultBilling = address.DefaultBilling || r.DefaultBilling
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// API key scopes. Admin users implicitly hold every scope.
+const (
+	ScopeOrdersRead    = "orders:read"
+	ScopeOrdersWrite   = "orders:write"
+	ScopeProductsWrite = "products:write"
+	ScopeUsersWrite    = "users:write"
+	ScopeSettingsRead  = "settings:read"
+	ScopeSettingsWrite = "settings:write"
+)
+
+// APIKeyScopes lists the scopes that can be granted to an API key
+var APIKeyScopes = []string{ScopeOrdersRead, ScopeOrdersWrite, ScopeProductsWrite, ScopeUsersWrite, ScopeSettingsRead, ScopeSettingsWrite}
+
+// ValidAPIKeyScope reports whether scope can be granted to an API key
+func ValidAPIKeyScope(scope string) bool {
+	for _, s := range APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes is a list of scopes stored as a space-separated string
+type Scopes []string
+
+// Has reports whether scope is in the list
+func (s Scopes) Has(scope string) bool {
+	for _, granted := range s {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Value implements driver.Valuer
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+// Scan implements sql.Scanner
+func (s *Scopes) Scan(value interface{}) error {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	case nil:
+	default:
+		return errors.New("scopes: unsupported column type")
+	}
+	*s = strings.Fields(str)
+	return nil
+}
+
+// APIKey lets scripts and other services call admin endpoints without a user login.
+// Only a hash of the key is stored; the prefix identifies the key for lookup and display.
+type APIKey struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null"`
+	Prefix      string         `json:"prefix" gorm:"not null;uniqueIndex"`
+	KeyHash     string         `json:"-" gorm:"not null"`
+	Scopes      Scopes         `json:"scopes" gorm:"type:text;not null;default:''" swaggertype:"array,string"`
+	CreatedByID uint           `json:"created_by_id" gorm:"not null"`
+	ExpiresAt   *time.Time     `json:"expires_at"`
+	LastUsedAt  *time.Time     `json:"last_used_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Expired reports whether the key can no longer be used
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// apiKeyPrefix marks API keys so leaked keys are easy to recognize, e.g. by secret scanners
+const apiKeyPrefix = "fa_"
+
+var apiKeyEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateAPIKey returns a new plaintext key of the form fa_<prefix>_<secret> and its lookup prefix
+func GenerateAPIKey() (key string, prefix string, err error) {
+	random := make([]byte, 5+32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+	prefix = strings.ToLower(apiKeyEncoding.EncodeToString(random[:5]))
+	secret := strings.ToLower(apiKeyEncoding.EncodeToString(random[5:]))
+	return apiKeyPrefix + prefix + "_" + secret, prefix, nil
+}
+
+// ParseAPIKeyPrefix returns the lookup prefix of a plaintext key
+func ParseAPIKeyPrefix(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0]+"_" != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// HashAPIKey returns the stored hash of a plaintext key. Keys carry 256 bits of randomness,
+// so a fast hash is sufficient.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKeyRequest represents the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100" example:"nightly-export"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,api_key_scope" example:"orders:read"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse includes the plaintext key, which is only returned once
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key" example:"fa_3kq9x2mz_Jm8l..."`
+}
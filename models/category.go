@@ -0,0 +1,128 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// CategoryRequest represents the payload for creating or updating a category
+// @Description Category create/update request payload
+type CategoryRequest struct {
+	Name     string `json:"name" validate:"required,max=100" example:"Laptops"`
+	Slug     string `json:"slug" validate:"max=100" example:"laptops"`
+	ParentID *uint  `json:"parent_id" example:"1"`
+}
+
+// Slugify converts a category name into a URL-friendly slug
+func Slugify(name string) string {
+	var b strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			lastDash = false
+		} else if !lastDash {
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// BuildCategoryTree nests a flat list of categories under their parents and returns the roots.
+// Categories whose parent is not in the list are treated as roots.
+func BuildCategoryTree(categories []Category) []Category {
+	byParent := make(map[uint][]Category)
+	known := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+
+	var roots []Category
+	for _, category := range categories {
+		if category.ParentID == nil || !known[*category.ParentID] {
+			roots = append(roots, category)
+			continue
+		}
+		byParent[*category.ParentID] = append(byParent[*category.ParentID], category)
+	}
+
+	var attach func(nodes []Category) []Category
+	attach = func(nodes []Category) []Category {
+		for i := range nodes {
+			nodes[i].Children = attach(byParent[nodes[i].ID])
+		}
+		return nodes
+	}
+
+	if roots == nil {
+		return []Category{}
+	}
+	return attach(roots)
+}
+
+// DescendantIDs returns the ID of the root category followed by the IDs of all its descendants
+func DescendantIDs(categories []Category, rootID uint) []uint {
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	visited := map[uint]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !visited[child] {
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// AncestorIDs returns the ID of the category followed by the IDs of its parent, grandparent and so on
+func AncestorIDs(categories []Category, categoryID uint) []uint {
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	ids := []uint{categoryID}
+	visited := map[uint]bool{categoryID: true}
+	for parent := parents[categoryID]; parent != nil && !visited[*parent]; parent = parents[*parent] {
+		visited[*parent] = true
+		ids = append(ids, *parent)
+	}
+	return ids
+}
+
+// CreatesCategoryCycle reports whether making parentID the parent of categoryID
+// would place the category underneath itself
+func CreatesCategoryCycle(categories []Category, categoryID, parentID uint) bool {
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	visited := make(map[uint]bool)
+	for current := &parentID; current != nil; current = parents[*current] {
+		if *current == categoryID || visited[*current] {
+			return true
+		}
+		visited[*current] = true
+	}
+	return false
+}
+
+// BeforeSave derives the slug from the name when none is set
+func (c *Category) BeforeSave(tx *gorm.DB) error {
+	if c.Slug == "" {
+		c.Slug = Slugify(c.Name)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"go-fiber-api/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExchangeRate converts prices from the store currency into another currency for display
+// @Description Exchange rate from the store currency
+type ExchangeRate struct {
+	ID       uint   `json:"id" gorm:"primaryKey" example:"1"`
+	Currency string `json:"currency" gorm:"type:varchar(3);uniqueIndex;not null" example:"EUR"`
+	// Rate is the number of units of Currency for one unit of the store currency
+	Rate string `json:"rate" gorm:"type:numeric(20,10);not null" example:"0.9215"`
+	// RoundingIncrement is the multiple of minor units converted amounts are rounded to
+	RoundingIncrement int64     `json:"rounding_increment" gorm:"not null;default:1" example:"1"`
+	CreatedAt         time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt         time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// ExchangeRateRequest represents the payload for setting the rate of a currency
+// @Description Exchange rate update request payload
+type ExchangeRateRequest struct {
+	Rate              string `json:"rate" validate:"required" example:"0.9215"`
+	RoundingIncrement int64  `json:"rounding_increment" validate:"omitempty,min=1" example:"5"`
+}
+
+// MoneyRate returns the rate for converting amounts in the base currency
+func (r ExchangeRate) MoneyRate(base string) (money.Rate, error) {
+	value, err := money.ParseRate(r.Rate)
+	if err != nil {
+		return money.Rate{}, err
+	}
+	return money.Rate{From: base, To: r.Currency, Value: value, Increment: r.RoundingIncrement}, nil
+}
+
+// ConvertPrices converts the product price and the prices of its loaded variants
+func (p *Product) ConvertPrices(rate money.Rate) {
+	p.Price = rate.Convert(p.Price)
+	for i := range p.Variants {
+		p.Variants[i].PriceOverride = rate.Convert(p.Variants[i].PriceOverride)
+		p.Variants[i].Price = rate.Convert(p.Variants[i].Price)
+	}
+}
+
+// ConvertPrices converts the order amounts and item prices. The tax total and the total are
+// derived from the converted parts, so the response adds up in the currency it is shown in.
+// Item prices are converted per unit and can differ from the subtotal by rounding.
+func (r *OrderResponse) ConvertPrices(rate money.Rate) {
+	r.Subtotal = rate.Convert(r.Subtotal)
+	r.ShippingCost = rate.Convert(r.ShippingCost)
+	if r.RefundedTotal != nil {
+		refunded := rate.Convert(*r.RefundedTotal)
+		r.RefundedTotal = &refunded
+	}
+	for i := range r.Items {
+		r.Items[i].Price = rate.Convert(r.Items[i].Price)
+	}
+
+	// Tax lines are copied so the order's own lines are not converted along
+	lines := make([]OrderTaxLine, 0, len(r.TaxLines))
+	taxTotal := rate.Convert(r.TaxTotal)
+	for i, line := range r.TaxLines {
+		line.Amount = rate.Convert(line.Amount)
+		lines = append(lines, line)
+		if i == 0 {
+			taxTotal = line.Amount
+		} else {
+			taxTotal = taxTotal.Add(line.Amount)
+		}
+	}
+	r.TaxLines = lines
+	r.TaxTotal = taxTotal
+
+	// Orders placed before subtotals were recorded only have a total
+	if r.Subtotal.IsZero() {
+		r.Total = rate.Convert(r.Total)
+		return
+	}
+	r.Total = r.Subtotal
+	for _, part := range []money.Money{r.TaxTotal, r.ShippingCost} {
+		if !part.IsZero() {
+			r.Total = r.Total.Add(part)
+		}
+	}
+}
+
+// SnapshotRate records the rate the order is placed with
+func (o *Order) SnapshotRate(rate money.Rate) {
+	o.Currency = rate.To
+	o.ExchangeRate = money.FormatRate(rate.Value)
+	o.RoundingIncrement = rate.Increment
+}
+
+// Rate returns the rate recorded when the order was placed. Orders placed before rates were
+// recorded were placed in the currency of their total.
+func (o Order) Rate() money.Rate {
+	value, err := money.ParseRate(o.ExchangeRate)
+	if o.Currency == "" || err != nil {
+		return money.Identity(o.Total.Currency)
+	}
+	return money.Rate{From: o.Total.Currency, To: o.Currency, Value: value, Increment: o.RoundingIncrement}
+}
+
+// AfterFind drops the trailing zeros the database pads rates with
+func (r *ExchangeRate) AfterFind(tx *gorm.DB) error {
+	r.Rate = normalizeRate(r.Rate)
+	return nil
+}
+
+// AfterFind drops the trailing zeros the database pads rates with
+func (o *Order) AfterFind(tx *gorm.DB) error {
+	o.ExchangeRate = normalizeRate(o.ExchangeRate)
+	return nil
+}
+
+func normalizeRate(rate string) string {
+	if value, err := money.ParseRate(rate); err == nil {
+		return money.FormatRate(value)
+	}
+	return rate
+}
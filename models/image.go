@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ProductImage represents an uploaded product image and its generated thumbnail
+// @Description Product image information
+type ProductImage struct {
+	ID           uint      `json:"id" gorm:"primaryKey" example:"1"`
+	ProductID    uint      `json:"product_id" gorm:"not null;index" example:"1"`
+	Key          string    `json:"-" gorm:"not null"`
+	ThumbnailKey string    `json:"-" gorm:"not null"`
+	URL          string    `json:"url" gorm:"not null" example:"/uploads/products/1/5f2b8c.jpg"`
+	ThumbnailURL string    `json:"thumbnail_url" gorm:"not null" example:"/uploads/products/1/5f2b8c_thumb.jpg"`
+	ContentType  string    `json:"content_type" example:"image/jpeg"`
+	Size         int64     `json:"size" example:"204800"`
+	Width        int       `json:"width" example:"1200"`
+	Height       int       `json:"height" example:"800"`
+	Position     int       `json:"position" example:"1"`
+	CreatedAt    time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
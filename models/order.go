@@ -0,0 +1,158 @@
+package models
+
+import (
+	"go-fiber-api/money"
+	"strings"
+	"time"
+)
+
+// Order statuses. Shipments move an order from pending through partially_shipped and
+// shipped to delivered; only pending orders can be cancelled.
+const (
+	OrderStatusPending          = "pending"
+	OrderStatusPartiallyShipped = "partially_shipped"
+	OrderStatusShipped          = "shipped"
+	OrderStatusDelivered        = "delivered"
+	OrderStatusCancelled        = "cancelled"
+)
+
+// CreateOrderRequest represents the payload for placing an order. When items are given the
+// total is calculated from catalog prices and any total sent is ignored.
+// @Description Order creation request payload
+type CreateOrderRequest struct {
+	Total *money.Money             `json:"total" validate:"required_without=Items" swaggertype:"object,string"`
+	Items []CreateOrderItemRequest `json:"items" validate:"omitempty,dive"`
+	// ShippingAddressID and BillingAddressID pick address book entries; the defaults are used
+	// when omitted. The shipping address decides which tax rules apply.
+	ShippingAddressID *uint  `json:"shipping_address_id" example:"1"`
+	BillingAddressID  *uint  `json:"billing_address_id" example:"1"`
+	ShippingMethod    string `json:"shipping_method" validate:"max=50" example:"standard"`
+}
+
+// CreateOrderItemRequest is one product line of a new order
+type CreateOrderItemRequest struct {
+	ProductID uint  `json:"product_id" validate:"required" example:"1"`
+	VariantID *uint `json:"variant_id" example:"4"`
+	Quantity  int   `json:"quantity" validate:"required,min=1" example:"2"`
+}
+
+// Order builds a pending order of the user from the request. The owner and status
+// never come from the payload.
+func (r CreateOrderRequest) Order(userID uint) Order {
+	order := Order{
+		UserID:         userID,
+		Status:         OrderStatusPending,
+		ShippingMethod: strings.TrimSpace(r.ShippingMethod),
+	}
+	if r.Total != nil {
+		order.Total = *r.Total
+	}
+	for _, item := range r.Items {
+		order.Items = append(order.Items, OrderItem{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+	return order
+}
+
+// OrderResponse is the public representation of an order
+// @Description Order information
+type OrderResponse struct {
+	ID     uint                `json:"id" example:"1"`
+	UserID uint                `json:"user_id" example:"1"`
+	Status string              `json:"status" example:"pending"`
+	Items  []OrderItemResponse `json:"items"`
+	// Subtotal excludes tax and shipping and Total includes them, whichever TaxMode item prices were in
+	Subtotal        money.Money    `json:"subtotal" swaggertype:"object,string"`
+	TaxLines        []OrderTaxLine `json:"tax_lines"`
+	TaxTotal        money.Money    `json:"tax_total" swaggertype:"object,string"`
+	ShippingCost    money.Money    `json:"shipping_cost" swaggertype:"object,string"`
+	Total           money.Money    `json:"total" swaggertype:"object,string"`
+	RefundedTotal   *money.Money   `json:"refunded_total,omitempty" swaggertype:"object,string"`
+	TaxMode         string         `json:"tax_mode" example:"exclusive"`
+	ShippingAddress *Address       `json:"shipping_address,omitempty"`
+	BillingAddress  *Address       `json:"billing_address,omitempty"`
+	ShippingMethod  string         `json:"shipping_method,omitempty" example:"standard"`
+	// Currency and ExchangeRate are what the order was placed in, whatever currency it is shown in
+	Currency     string    `json:"currency" example:"EUR"`
+	ExchangeRate string    `json:"exchange_rate" example:"0.9215"`
+	CreatedAt    time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt    time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// OrderItemResponse is one product line of an order response
+// @Description Order line item information
+type OrderItemResponse struct {
+	ID        uint        `json:"id" example:"1"`
+	ProductID uint        `json:"product_id" example:"1"`
+	VariantID *uint       `json:"variant_id,omitempty" example:"4"`
+	SKU       string      `json:"sku,omitempty" example:"TSHIRT-BLK-M"`
+	Quantity  int         `json:"quantity" example:"2"`
+	Price     money.Money `json:"price" swaggertype:"object,string"`
+}
+
+// NewOrderResponse maps an order and its loaded items to their public representation
+func NewOrderResponse(order Order) OrderResponse {
+	items := make([]OrderItemResponse, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, OrderItemResponse{
+			ID:        item.ID,
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			SKU:       item.SKU,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+		})
+	}
+	taxLines := order.TaxLines
+	if taxLines == nil {
+		taxLines = []OrderTaxLine{}
+	}
+
+	rate := order.Rate()
+	return OrderResponse{
+		ID:              order.ID,
+		UserID:          order.UserID,
+		Status:          order.Status,
+		Items:           items,
+		Subtotal:        order.Subtotal,
+		TaxLines:        taxLines,
+		TaxTotal:        order.TaxTotal,
+		ShippingCost:    order.ShippingCost,
+		Total:           order.Total,
+		RefundedTotal:   moneyOrNil(order.RefundedTotal),
+		TaxMode:         order.TaxMode,
+		ShippingAddress: addressOrNil(order.ShippingAddress),
+		BillingAddress:  addressOrNil(order.BillingAddress),
+		ShippingMethod:  order.ShippingMethod,
+		Currency:        rate.To,
+		ExchangeRate:    money.FormatRate(rate.Value),
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+	}
+}
+
+// NewOrderResponses maps a list of orders to their public representation
+func NewOrderResponses(orders []Order) []OrderResponse {
+	responses := make([]OrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, NewOrderResponse(order))
+	}
+	return responses
+}
+
+func moneyOrNil(m money.Money) *money.Money {
+	if m.IsZero() {
+		return nil
+	}
+	return &m
+}
+
+func addressOrNil(address Address) *Address {
+	if address.IsZero() {
+		return nil
+	}
+	return &address
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"go-fiber-api/money"
+	"go-fiber-api/tax"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestCreateOrderRequestIgnoresOwnerAndStatus(t *testing.T) {
+	var req CreateOrderRequest
+	body := `{"total": {"amount": "10.00", "currency": "USD"}, "user_id": 99, "status": "delivered", "items": [{"product_id": 3, "quantity": 2, "price": 0.01}]}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("Could not decode request: %v", err)
+	}
+
+	order := req.Order(7)
+	if order.UserID != 7 || order.Status != "pending" || order.Total.Amount != 1000 {
+		t.Errorf("Expected a pending 10.00 order of user 7, got user %d status %q total %s", order.UserID, order.Status, order.Total)
+	}
+	if len(order.Items) != 1 || order.Items[0].ProductID != 3 || order.Items[0].Quantity != 2 || !order.Items[0].Price.IsZero() {
+		t.Errorf("Unexpected items %+v", order.Items)
+	}
+}
+
+func TestResponsesDoNotExposeInternalFields(t *testing.T) {
+	user := User{ID: 1, Email: "jane@example.com", Password: "hash", TOTPSecret: "secret", Orders: []Order{{ID: 5}}}
+	data, _ := json.Marshal(NewUserResponse(user))
+	for _, field := range []string{"password", "totp", "orders", "hash", "secret"} {
+		if strings.Contains(string(data), field) {
+			t.Errorf("Expected user response without %q, got %s", field, data)
+		}
+	}
+
+	order := Order{ID: 5, UserID: 1, User: user, Items: []OrderItem{{ID: 2, ProductID: 3, Product: Product{Name: "Laptop"}}}}
+	data, _ = json.Marshal(NewOrderResponse(order))
+	for _, field := range []string{`"user"`, `"product"`, "Laptop"} {
+		if strings.Contains(string(data), field) {
+			t.Errorf("Expected order response without %s, got %s", field, data)
+		}
+	}
+}
+
+func TestOrderKeepsRateItWasPlacedWith(t *testing.T) {
+	rate := money.Rate{From: "USD", To: "EUR", Value: big.NewRat(92, 100), Increment: 1}
+	order := Order{Total: money.MustParse("100.00", "USD"), Items: []OrderItem{{Quantity: 1, Price: money.MustParse("100.00", "USD")}}}
+	order.SnapshotRate(rate)
+
+	response := NewOrderResponse(order)
+	response.ConvertPrices(order.Rate())
+	if response.Currency != "EUR" || response.ExchangeRate != "0.92" {
+		t.Errorf("Expected the EUR rate 0.92 on the response, got %s %s", response.Currency, response.ExchangeRate)
+	}
+	if response.Total.String() != "92.00 EUR" || response.Items[0].Price.String() != "92.00 EUR" {
+		t.Errorf("Expected amounts of 92.00 EUR, got %s and %s", response.Total, response.Items[0].Price)
+	}
+
+	legacy := Order{Total: money.MustParse("5.00", "USD")}
+	if got := legacy.Rate().Convert(legacy.Total); got != legacy.Total {
+		t.Errorf("Expected orders without a recorded rate to stay in their currency, got %s", got)
+	}
+}
+
+func TestOrderResponseShowsTaxBreakdown(t *testing.T) {
+	breakdown := tax.Breakdown{
+		Subtotal: money.MustParse("100.00", "USD"),
+		Lines:    []tax.Line{{Name: "VAT", Rate: big.NewRat(19, 1), Amount: money.MustParse("19.00", "USD")}},
+		Tax:      money.MustParse("19.00", "USD"),
+		Total:    money.MustParse("119.00", "USD"),
+	}
+	order := Order{ShippingAddress: Address{Country: "DE"}}
+	order.ApplyTax(breakdown, tax.ModeExclusive)
+
+	response := NewOrderResponse(order)
+	if response.Subtotal != breakdown.Subtotal || response.TaxTotal != breakdown.Tax || response.Total != breakdown.Total {
+		t.Errorf("Expected 100.00 + 19.00 = 119.00, got %s + %s = %s", response.Subtotal, response.TaxTotal, response.Total)
+	}
+	if len(response.TaxLines) != 1 || response.TaxLines[0].Name != "VAT" || response.TaxLines[0].Rate != "19" {
+		t.Errorf("Expected a VAT 19 line, got %+v", response.TaxLines)
+	}
+
+	response.ConvertPrices(money.Rate{From: "USD", To: "EUR", Value: big.NewRat(1, 2)})
+	if response.TaxLines[0].Amount.String() != "9.50 EUR" || order.TaxLines[0].Amount.String() != "19.00 USD" {
+		t.Errorf("Expected only the response's tax line to be converted, got %s and %s", response.TaxLines[0].Amount, order.TaxLines[0].Amount)
+	}
+}
+
+func TestConvertedOrderResponseAddsUp(t *testing.T) {
+	usd := func(amount string) money.Money { return money.MustParse(amount, "USD") }
+	response := OrderResponse{
+		Subtotal:     usd("10.01"),
+		TaxLines:     []OrderTaxLine{{Name: "VAT", Amount: usd("0.50")}, {Name: "City", Amount: usd("0.51")}},
+		TaxTotal:     usd("1.01"),
+		ShippingCost: usd("5.01"),
+		Total:        usd("16.03"),
+	}
+
+	// Converted on its own the total would be 5.34 EUR
+	response.ConvertPrices(money.Rate{From: "USD", To: "EUR", Value: big.NewRat(1, 3)})
+	if response.TaxTotal.String() != "0.34 EUR" {
+		t.Errorf("Expected the tax total to be the sum of the converted lines 0.17 + 0.17, got %s", response.TaxTotal)
+	}
+	if response.Total.String() != "5.35 EUR" {
+		t.Errorf("Expected 3.34 + 0.34 + 1.67 = 5.35 EUR, got %s", response.Total)
+	}
+}
+
+func TestOrderResponseOmitsMissingAddresses(t *testing.T) {
+	data, _ := json.Marshal(NewOrderResponse(Order{ID: 1}))
+	if strings.Contains(string(data), "shipping_address") || strings.Contains(string(data), "billing_address") {
+		t.Errorf("Expected orders without addresses to omit them, got %s", data)
+	}
+
+	shipTo := Address{Name: "Jane Doe", Line1: "1 Main St", City: "Springfield", Country: "US"}
+	response := NewOrderResponse(Order{ID: 2, ShippingAddress: shipTo, BillingAddress: shipTo})
+	if response.ShippingAddress == nil || *response.ShippingAddress != shipTo || response.BillingAddress == nil {
+		t.Errorf("Expected the address snapshot, got %+v", response.ShippingAddress)
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// UpdateProfileRequest represents the payload for updating the user's profile
+// @Description Profile update request payload
+type UpdateProfileRequest struct {
+	FirstName string `json:"first_name" validate:"required,max=100" example:"John"`
+	LastName  string `json:"last_name" validate:"required,max=100" example:"Doe"`
+}
+
+// Apply copies the editable profile fields onto the user; everything else, such as the role, stays untouched
+func (r UpdateProfileRequest) Apply(user *User) {
+	user.FirstName = r.FirstName
+	user.LastName = r.LastName
+}
+
+// UserResponse is the public representation of a user account
+// @Description User account information
+type UserResponse struct {
+	ID               uint       `json:"id" example:"1"`
+	Email            string     `json:"email" example:"user@example.com"`
+	FirstName        string     `json:"first_name" example:"John"`
+	LastName         string     `json:"last_name" example:"Doe"`
+	Role             string     `json:"role" example:"user"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at" example:"2023-01-01T00:00:00Z"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled" example:"false"`
+	CreatedAt        time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt        time.Time  `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// NewUserResponse maps a user to its public representation
+func NewUserResponse(user User) UserResponse {
+	return UserResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Role:             user.Role,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+}
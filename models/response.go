@@ -1,9 +1,16 @@
 package models
 
-// ErrorResponse represents a standard error response
-// @Description Standard error response format
-type ErrorResponse struct {
-	Error string `json:"error" example:"Error message"`
+// Problem is the RFC 7807 problem detail returned for every error
+// @Description Error response (application/problem+json)
+type Problem struct {
+	Type      string       `json:"type" example:"urn:problem-type:order_not_found"`
+	Title     string       `json:"title" example:"Not Found"`
+	Status    int          `json:"status" example:"404"`
+	Detail    string       `json:"detail" example:"Order not found"`
+	Code      string       `json:"code" example:"order_not_found"`
+	Instance  string       `json:"instance,omitempty" example:"/api/orders/42"`
+	RequestID string       `json:"request_id,omitempty" example:"6f1c2a7e-8d7b-4c1e-9a55-2f0b5d9e4c31"`
+	Errors    []FieldError `json:"errors,omitempty"`
 }
 
 // MessageResponse represents a standard success message response
@@ -17,3 +24,39 @@ type MessageResponse struct {
 type TokenResponse struct {
 	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
 }
+
+// Pagination describes the current page of a paginated list
+// @Description Pagination metadata
+type Pagination struct {
+	Page  int   `json:"page" example:"1"`
+	Limit int   `json:"limit" example:"10"`
+	Total int64 `json:"total" example:"42"`
+}
+
+// ReviewListResponse represents a paginated list of product reviews
+// @Description Paginated product reviews
+type ReviewListResponse struct {
+	Reviews    []Review   `json:"reviews"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// OrderListResponse represents a paginated list of orders
+// @Description Paginated orders
+type OrderListResponse struct {
+	Orders     []OrderResponse `json:"orders"`
+	Pagination Pagination      `json:"pagination"`
+}
+
+// ReturnListResponse represents a paginated list of return requests
+// @Description Paginated return requests
+type ReturnListResponse struct {
+	Returns    []OrderReturn `json:"returns"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+// FieldError describes why one field of a request failed validation
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Rule    string `json:"rule" example:"email"`
+	Message string `json:"message" example:"must be a valid email address"`
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"go-fiber-api/money"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Return statuses. Customers request returns; admins approve or reject them.
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+)
+
+// OrderReturn is a customer's request to send back delivered items of an order (an RMA)
+// @Description Return request information
+type OrderReturn struct {
+	ID         uint              `json:"id" gorm:"primaryKey" example:"1"`
+	OrderID    uint              `json:"order_id" gorm:"not null;index" example:"1"`
+	UserID     uint              `json:"user_id" gorm:"not null;index" example:"1"`
+	Status     string            `json:"status" gorm:"not null;default:requested;index" example:"requested"`
+	Reason     string            `json:"reason" gorm:"not null" example:"Arrived damaged"`
+	Items      []OrderReturnItem `json:"items" gorm:"foreignKey:ReturnID"`
+	Note       string            `json:"note" gorm:"not null;default:''" example:"Refunded without return shipping"`
+	Refund     *Refund           `json:"refund,omitempty" gorm:"foreignKey:ReturnID"`
+	ResolvedAt *time.Time        `json:"resolved_at" example:"2023-01-10T00:00:00Z"`
+	CreatedAt  time.Time         `json:"created_at" example:"2023-01-08T00:00:00Z"`
+	UpdatedAt  time.Time         `json:"updated_at" example:"2023-01-08T00:00:00Z"`
+}
+
+// OrderReturnItem is the quantity of one order item in a return
+// @Description Returned order item
+type OrderReturnItem struct {
+	ID          uint `json:"-" gorm:"primaryKey"`
+	ReturnID    uint `json:"-" gorm:"not null;index"`
+	OrderItemID uint `json:"order_item_id" gorm:"not null;index" example:"1"`
+	Quantity    int  `json:"quantity" gorm:"not null" example:"1"`
+}
+
+// Refund is money paid back on an order for an approved return
+// @Description Refund information
+type Refund struct {
+	ID        uint        `json:"id" gorm:"primaryKey" example:"1"`
+	OrderID   uint        `json:"order_id" gorm:"not null;index" example:"1"`
+	ReturnID  *uint       `json:"return_id,omitempty" gorm:"index" example:"1"`
+	Amount    money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_" swaggertype:"object,string"`
+	Reason    string      `json:"reason" gorm:"not null;default:''" example:"Return approved"`
+	CreatedAt time.Time   `json:"created_at" example:"2023-01-10T00:00:00Z"`
+}
+
+// CreateReturnRequest represents the payload for requesting a return
+// @Description Return request payload
+type CreateReturnRequest struct {
+	Reason string              `json:"reason" validate:"required,max=500" example:"Arrived damaged"`
+	Items  []ReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// ReturnItemRequest is one order item of a return request
+type ReturnItemRequest struct {
+	OrderItemID uint `json:"order_item_id" validate:"required" example:"1"`
+	Quantity    int  `json:"quantity" validate:"required,min=1" example:"1"`
+}
+
+// ApproveReturnRequest represents the payload for approving a return. The refund defaults to
+// the returned items' share of the order total and restocking defaults to true.
+// @Description Return approval request payload
+type ApproveReturnRequest struct {
+	Refund  *money.Money `json:"refund" swaggertype:"object,string"`
+	Restock *bool        `json:"restock" example:"true"`
+	Note    string       `json:"note" validate:"max=500" example:"Refunded without return shipping"`
+}
+
+// RejectReturnRequest represents the payload for rejecting a return
+// @Description Return rejection request payload
+type RejectReturnRequest struct {
+	Note string `json:"note" validate:"max=500" example:"Returned after the return period"`
+}
+
+// Return builds a return of the order from the request. returnable holds the quantity of each
+// order item that was delivered and is not in another pending or approved return.
+func (r CreateReturnRequest) Return(order Order, returnable map[uint]int) (OrderReturn, []FieldError) {
+	ret := OrderReturn{
+		OrderID: order.ID,
+		UserID:  order.UserID,
+		Status:  ReturnStatusRequested,
+		Reason:  strings.TrimSpace(r.Reason),
+	}
+
+	quantities := make([]itemQuantity, 0, len(r.Items))
+	for _, item := range r.Items {
+		quantities = append(quantities, itemQuantity{item.OrderItemID, item.Quantity})
+	}
+	fields := checkQuantities(order, quantities, returnable, "returnable", "delivered and not yet returned")
+	if fields == nil {
+		for _, item := range r.Items {
+			ret.Items = append(ret.Items, OrderReturnItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+		}
+	}
+	return ret, fields
+}
+
+// ReturnableQuantities returns the quantity of each order item that can still be returned:
+// what was delivered less what is in returns that were not rejected. Orders delivered before
+// shipments were recorded count as delivered in full.
+func ReturnableQuantities(order Order, shipments []Shipment, returns []OrderReturn) map[uint]int {
+	delivered := ShippedQuantities(shipments, true)
+	if len(shipments) == 0 && order.Status == OrderStatusDelivered {
+		for _, item := range order.Items {
+			delivered[item.ID] = item.Quantity
+		}
+	}
+
+	for itemID, quantity := range ReturnedQuantities(returns) {
+		delivered[itemID] -= quantity
+	}
+	return delivered
+}
+
+// ReturnedQuantities sums the quantity of each order item over the returns that were not rejected
+func ReturnedQuantities(returns []OrderReturn) map[uint]int {
+	quantities := map[uint]int{}
+	for _, ret := range returns {
+		if ret.Status == ReturnStatusRejected {
+			continue
+		}
+		for _, item := range ret.Items {
+			quantities[item.OrderItemID] += item.Quantity
+		}
+	}
+	return quantities
+}
+
+// Refundable returns how much of the order total has not been refunded yet
+func (o Order) Refundable() money.Money {
+	if o.RefundedTotal.IsZero() {
+		return o.Total
+	}
+	return o.Total.Sub(o.RefundedTotal)
+}
+
+// AddRefund adds a refund to the order's refunded total
+func (o *Order) AddRefund(amount money.Money) {
+	if o.RefundedTotal.IsZero() {
+		o.RefundedTotal = money.New(0, o.Total.Currency)
+	}
+	o.RefundedTotal = o.RefundedTotal.Add(amount)
+}
+
+// ReturnRefund returns the returned items' share of what was paid for the goods, so tax is
+// refunded along and shipping is not. It never exceeds what is left to refund.
+func (o Order) ReturnRefund(items []OrderReturnItem) money.Money {
+	goods := o.Total
+	if !o.ShippingCost.IsZero() {
+		goods = goods.Sub(o.ShippingCost)
+	}
+
+	prices := make(map[uint]money.Money, len(o.Items))
+	var value, returned int64
+	for _, item := range o.Items {
+		prices[item.ID] = item.Price
+		value += item.Price.Mul(int64(item.Quantity)).Amount
+	}
+	for _, item := range items {
+		returned += prices[item.OrderItemID].Mul(int64(item.Quantity)).Amount
+	}
+	if value == 0 {
+		return money.New(0, o.Total.Currency)
+	}
+
+	share := new(big.Rat).SetFrac64(goods.Amount, 1)
+	share.Mul(share, big.NewRat(returned, value))
+	refund := money.FromRat(share, o.Total.Currency)
+	if refundable := o.Refundable(); refund.Cmp(refundable) > 0 {
+		return refundable
+	}
+	return refund
+}
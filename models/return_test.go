@@ -0,0 +1,64 @@
+package models
+
+import (
+	"go-fiber-api/money"
+	"testing"
+	"time"
+)
+
+func TestReturnableQuantities(t *testing.T) {
+	delivered := time.Now()
+	order := Order{Status: OrderStatusShipped, Items: []OrderItem{{ID: 1, Quantity: 3}, {ID: 2, Quantity: 1}}}
+	shipments := []Shipment{
+		{Items: []ShipmentItem{{OrderItemID: 1, Quantity: 3}}, DeliveredAt: &delivered},
+		{Items: []ShipmentItem{{OrderItemID: 2, Quantity: 1}}},
+	}
+	returns := []OrderReturn{
+		{Status: ReturnStatusApproved, Items: []OrderReturnItem{{OrderItemID: 1, Quantity: 1}}},
+		{Status: ReturnStatusRejected, Items: []OrderReturnItem{{OrderItemID: 1, Quantity: 2}}},
+	}
+
+	returnable := ReturnableQuantities(order, shipments, returns)
+	if returnable[1] != 2 || returnable[2] != 0 {
+		t.Errorf("Expected 2 of item 1 and none of the undelivered item 2, got %v", returnable)
+	}
+
+	req := CreateReturnRequest{Reason: "Too small", Items: []ReturnItemRequest{{OrderItemID: 2, Quantity: 1}}}
+	if _, fields := req.Return(order, returnable); len(fields) != 1 || fields[0].Rule != "returnable" {
+		t.Errorf("Expected undelivered items not to be returnable, got %+v", fields)
+	}
+
+	if returned := ReturnedQuantities(returns); returned[1] != 1 || len(returned) != 1 {
+		t.Errorf("Expected only the approved return to count as returned, got %v", returned)
+	}
+
+	legacy := Order{Status: OrderStatusDelivered, Items: order.Items}
+	if returnable := ReturnableQuantities(legacy, nil, nil); returnable[1] != 3 || returnable[2] != 1 {
+		t.Errorf("Expected orders delivered without shipments to be returnable in full, got %v", returnable)
+	}
+}
+
+func TestReturnRefund(t *testing.T) {
+	usd := func(amount string) money.Money { return money.MustParse(amount, "USD") }
+	order := Order{
+		Total:        usd("115.00"),
+		ShippingCost: usd("5.00"),
+		Items: []OrderItem{
+			{ID: 1, Quantity: 2, Price: usd("30.00")},
+			{ID: 2, Quantity: 1, Price: usd("40.00")},
+		},
+	}
+
+	// One of the 100.00 of goods worth 30.00 gets its share of the 110.00 paid with tax
+	if got := order.ReturnRefund([]OrderReturnItem{{OrderItemID: 1, Quantity: 1}}); got != usd("33.00") {
+		t.Errorf("Expected a refund of 33.00, got %s", got)
+	}
+
+	order.AddRefund(usd("100.00"))
+	if got := order.Refundable(); got != usd("15.00") {
+		t.Errorf("Expected 15.00 left to refund, got %s", got)
+	}
+	if got := order.ReturnRefund([]OrderReturnItem{{OrderItemID: 2, Quantity: 1}}); got != usd("15.00") {
+		t.Errorf("Expected the refund to be capped at what is left, got %s", got)
+	}
+}
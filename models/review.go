@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Review represents a customer's rating and feedback for a product
+// @Description Product review information
+type Review struct {
+	ID        uint           `json:"id" gorm:"primaryKey" example:"1"`
+	ProductID uint           `json:"product_id" gorm:"not null;uniqueIndex:idx_reviews_product_user" example:"1"`
+	UserID    uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_reviews_product_user" example:"1"`
+	Rating    int            `json:"rating" gorm:"not null" example:"5"`
+	Text      string         `json:"text" example:"Great product, fast delivery"`
+	CreatedAt time.Time      `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt time.Time      `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CreateReviewRequest represents the payload for reviewing a product
+// @Description Product review request payload
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5" example:"5"`
+	Text   string `json:"text" validate:"max=5000" example:"Great product, fast delivery"`
+}
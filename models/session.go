@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Session is a login of a user on one device. Access tokens reference their session through
+// the jti claim, so revoking the session signs the device out.
+type Session struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"-" gorm:"not null;index"`
+	TokenID    string     `json:"-" gorm:"not null;uniqueIndex"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"-"`
+	// Current marks the session of the request listing the sessions
+	Current bool `json:"current" gorm:"-"`
+}
+
+// Active reports whether tokens of the session are still accepted
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Shipment is a parcel with some or all of an order's items, handed to a carrier
+// @Description Shipment information
+type Shipment struct {
+	ID             uint           `json:"id" gorm:"primaryKey" example:"1"`
+	OrderID        uint           `json:"order_id" gorm:"not null;index" example:"1"`
+	Carrier        string         `json:"carrier" gorm:"not null" example:"DHL"`
+	TrackingNumber string         `json:"tracking_number" gorm:"not null;default:''" example:"00340434161094042557"`
+	Items          []ShipmentItem `json:"items" gorm:"foreignKey:ShipmentID"`
+	ShippedAt      time.Time      `json:"shipped_at" example:"2023-01-02T00:00:00Z"`
+	DeliveredAt    *time.Time     `json:"delivered_at" example:"2023-01-04T00:00:00Z"`
+	CreatedAt      time.Time      `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt      time.Time      `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// ShipmentItem is the quantity of one order item in a shipment
+// @Description Shipped order item
+type ShipmentItem struct {
+	ID          uint `json:"-" gorm:"primaryKey"`
+	ShipmentID  uint `json:"-" gorm:"not null;index"`
+	OrderItemID uint `json:"order_item_id" gorm:"not null;index" example:"1"`
+	Quantity    int  `json:"quantity" gorm:"not null" example:"2"`
+}
+
+// CreateShipmentRequest represents the payload for recording a shipment of an order.
+// ShippedAt defaults to now. Items are required unless the order was placed without items,
+// in which case the shipment covers the whole order.
+// @Description Shipment creation request payload
+type CreateShipmentRequest struct {
+	Carrier        string                `json:"carrier" validate:"required,max=50" example:"DHL"`
+	TrackingNumber string                `json:"tracking_number" validate:"max=100" example:"00340434161094042557"`
+	Items          []ShipmentItemRequest `json:"items" validate:"omitempty,dive"`
+	ShippedAt      *time.Time            `json:"shipped_at" example:"2023-01-02T00:00:00Z"`
+}
+
+// ShipmentItemRequest is one order item of a new shipment
+type ShipmentItemRequest struct {
+	OrderItemID uint `json:"order_item_id" validate:"required" example:"1"`
+	Quantity    int  `json:"quantity" validate:"required,min=1" example:"2"`
+}
+
+// UpdateShipmentRequest represents the payload for updating a shipment's tracking details or
+// marking it delivered. ShippedAt and DeliveredAt are kept when omitted; ClearDeliveredAt
+// marks a shipment recorded as delivered by mistake as undelivered again.
+// @Description Shipment update request payload
+type UpdateShipmentRequest struct {
+	Carrier          string     `json:"carrier" validate:"required,max=50" example:"DHL"`
+	TrackingNumber   string     `json:"tracking_number" validate:"max=100" example:"00340434161094042557"`
+	ShippedAt        *time.Time `json:"shipped_at" example:"2023-01-02T00:00:00Z"`
+	DeliveredAt      *time.Time `json:"delivered_at" example:"2023-01-04T00:00:00Z"`
+	ClearDeliveredAt bool       `json:"clear_delivered_at" validate:"excluded_with=DeliveredAt" example:"false"`
+}
+
+// Shipment builds a shipment of the order from the request. shipped holds the quantities of
+// each order item already in other shipments; items that are not part of the order or more
+// than what is left to ship are reported as field errors.
+func (r CreateShipmentRequest) Shipment(order Order, shipped map[uint]int, now time.Time) (Shipment, []FieldError) {
+	shipment := Shipment{
+		OrderID:        order.ID,
+		Carrier:        strings.TrimSpace(r.Carrier),
+		TrackingNumber: strings.TrimSpace(r.TrackingNumber),
+		ShippedAt:      now,
+	}
+	if r.ShippedAt != nil {
+		shipment.ShippedAt = *r.ShippedAt
+	}
+
+	left := make(map[uint]int, len(order.Items))
+	for _, item := range order.Items {
+		left[item.ID] = item.Quantity - shipped[item.ID]
+	}
+
+	if len(order.Items) > 0 && len(r.Items) == 0 {
+		return shipment, []FieldError{{Field: "items", Rule: "required", Message: "is required"}}
+	}
+	quantities := make([]itemQuantity, 0, len(r.Items))
+	for _, item := range r.Items {
+		quantities = append(quantities, itemQuantity{item.OrderItemID, item.Quantity})
+	}
+	fields := checkQuantities(order, quantities, left, "unshipped", "not yet shipped")
+	if fields == nil {
+		for _, item := range r.Items {
+			shipment.Items = append(shipment.Items, ShipmentItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+		}
+	}
+	return shipment, fields
+}
+
+// itemQuantity is a requested quantity of an order item
+type itemQuantity struct {
+	orderItemID uint
+	quantity    int
+}
+
+// checkQuantities reports the requested items that are not part of the order, or whose
+// quantities, summed over the request, exceed what is left of them. rule and what name the
+// limit in the errors, e.g. "not yet shipped".
+func checkQuantities(order Order, requested []itemQuantity, left map[uint]int, rule, what string) []FieldError {
+	inOrder := make(map[uint]bool, len(order.Items))
+	for _, item := range order.Items {
+		inOrder[item.ID] = true
+	}
+
+	var fields []FieldError
+	sums := map[uint]int{}
+	for i, item := range requested {
+		if !inOrder[item.orderItemID] {
+			fields = append(fields, FieldError{
+				Field:   fmt.Sprintf("items[%d].order_item_id", i),
+				Rule:    "order_item",
+				Message: "must be an item of this order",
+			})
+			continue
+		}
+
+		sums[item.orderItemID] += item.quantity
+		if sums[item.orderItemID] > left[item.orderItemID] {
+			fields = append(fields, FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Rule:    rule,
+				Message: fmt.Sprintf("must not exceed the %d %s", left[item.orderItemID], what),
+			})
+		}
+	}
+	return fields
+}
+
+// Apply copies the request onto the shipment
+func (r UpdateShipmentRequest) Apply(shipment *Shipment) {
+	shipment.Carrier = strings.TrimSpace(r.Carrier)
+	shipment.TrackingNumber = strings.TrimSpace(r.TrackingNumber)
+	if r.ShippedAt != nil {
+		shipment.ShippedAt = *r.ShippedAt
+	}
+	if r.DeliveredAt != nil {
+		shipment.DeliveredAt = r.DeliveredAt
+	} else if r.ClearDeliveredAt {
+		shipment.DeliveredAt = nil
+	}
+}
+
+// ShippedQuantities sums the quantity of each order item over the shipments. Only delivered
+// shipments are counted when delivered is set.
+func ShippedQuantities(shipments []Shipment, delivered bool) map[uint]int {
+	quantities := map[uint]int{}
+	for _, shipment := range shipments {
+		if delivered && shipment.DeliveredAt == nil {
+			continue
+		}
+		for _, item := range shipment.Items {
+			quantities[item.OrderItemID] += item.Quantity
+		}
+	}
+	return quantities
+}
+
+// FulfilledWithoutShipments reports whether the order was marked shipped or delivered before
+// shipments were recorded. Its items count as shipped, so no shipments can be added to it.
+func (o Order) FulfilledWithoutShipments(shipments []Shipment) bool {
+	return len(shipments) == 0 && (o.Status == OrderStatusShipped || o.Status == OrderStatusDelivered)
+}
+
+// FulfillmentStatus returns the status an order with the given items is in after its
+// shipments: delivered once every unit is in a delivered shipment, shipped once every unit is
+// in a shipment, partially_shipped when some are and pending when none are. Orders without
+// items ship as a whole: they are shipped with their first shipment and delivered once every
+// shipment is.
+func FulfillmentStatus(items []OrderItem, shipments []Shipment) string {
+	if len(items) == 0 {
+		if len(shipments) == 0 {
+			return OrderStatusPending
+		}
+		for _, shipment := range shipments {
+			if shipment.DeliveredAt == nil {
+				return OrderStatusShipped
+			}
+		}
+		return OrderStatusDelivered
+	}
+
+	shipped := ShippedQuantities(shipments, false)
+	delivered := ShippedQuantities(shipments, true)
+	if len(shipped) == 0 {
+		return OrderStatusPending
+	}
+
+	allShipped, allDelivered := true, true
+	for _, item := range items {
+		allShipped = allShipped && shipped[item.ID] >= item.Quantity
+		allDelivered = allDelivered && delivered[item.ID] >= item.Quantity
+	}
+	switch {
+	case allDelivered:
+		return OrderStatusDelivered
+	case allShipped:
+		return OrderStatusShipped
+	default:
+		return OrderStatusPartiallyShipped
+	}
+}
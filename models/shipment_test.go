@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreateShipmentRequestLimitsQuantities(t *testing.T) {
+	order := Order{ID: 4, Items: []OrderItem{{ID: 1, Quantity: 3}, {ID: 2, Quantity: 1}}}
+	now := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	req := CreateShipmentRequest{Carrier: " DHL ", Items: []ShipmentItemRequest{{OrderItemID: 1, Quantity: 2}}}
+	shipment, fields := req.Shipment(order, map[uint]int{1: 1}, now)
+	if fields != nil || shipment.OrderID != 4 || shipment.Carrier != "DHL" || !shipment.ShippedAt.Equal(now) || len(shipment.Items) != 1 {
+		t.Errorf("Expected a shipment of the 2 remaining units, got %+v %+v", shipment, fields)
+	}
+
+	req.Items = []ShipmentItemRequest{{OrderItemID: 1, Quantity: 1}, {OrderItemID: 1, Quantity: 2}, {OrderItemID: 9, Quantity: 1}}
+	_, fields = req.Shipment(order, map[uint]int{1: 1}, now)
+	if len(fields) != 2 || fields[0].Field != "items[1].quantity" || fields[1].Field != "items[2].order_item_id" {
+		t.Errorf("Expected over-shipping and a foreign item to fail, got %+v", fields)
+	}
+
+	req.Items = nil
+	if _, fields = req.Shipment(order, nil, now); len(fields) != 1 || fields[0].Field != "items" {
+		t.Errorf("Expected items to be required for an order with items, got %+v", fields)
+	}
+	if shipment, fields := req.Shipment(Order{ID: 5}, nil, now); fields != nil || len(shipment.Items) != 0 {
+		t.Errorf("Expected an order without items to ship as a whole, got %+v %+v", shipment, fields)
+	}
+}
+
+func TestUpdateShipmentRequestKeepsDeliveryUnlessCleared(t *testing.T) {
+	delivered := time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC)
+	shipment := Shipment{Carrier: "DHL", DeliveredAt: &delivered}
+
+	UpdateShipmentRequest{Carrier: "UPS", TrackingNumber: "1Z"}.Apply(&shipment)
+	if shipment.Carrier != "UPS" || shipment.DeliveredAt == nil || !shipment.DeliveredAt.Equal(delivered) {
+		t.Errorf("Expected a tracking update to keep the delivery date, got %+v", shipment)
+	}
+
+	UpdateShipmentRequest{Carrier: "UPS", ClearDeliveredAt: true}.Apply(&shipment)
+	if shipment.DeliveredAt != nil {
+		t.Errorf("Expected clear_delivered_at to clear the delivery date, got %v", shipment.DeliveredAt)
+	}
+}
+
+func TestFulfilledWithoutShipments(t *testing.T) {
+	legacy := Order{Status: OrderStatusDelivered}
+	if !legacy.FulfilledWithoutShipments(nil) {
+		t.Error("Expected an order delivered without shipments to take no shipments")
+	}
+	if legacy.FulfilledWithoutShipments([]Shipment{{}}) || (Order{Status: OrderStatusPending}).FulfilledWithoutShipments(nil) {
+		t.Error("Expected orders with shipments or still pending to take shipments")
+	}
+}
+
+func TestFulfillmentStatus(t *testing.T) {
+	items := []OrderItem{{ID: 1, Quantity: 2}, {ID: 2, Quantity: 1}}
+	delivered := time.Now()
+	first := Shipment{Items: []ShipmentItem{{OrderItemID: 1, Quantity: 2}}}
+	second := Shipment{Items: []ShipmentItem{{OrderItemID: 2, Quantity: 1}}}
+
+	cases := []struct {
+		name      string
+		shipments []Shipment
+		expected  string
+	}{
+		{"no shipments", nil, OrderStatusPending},
+		{"some items shipped", []Shipment{first}, OrderStatusPartiallyShipped},
+		{"all items shipped", []Shipment{first, second}, OrderStatusShipped},
+		{"some shipments delivered", []Shipment{first, {Items: second.Items, DeliveredAt: &delivered}}, OrderStatusShipped},
+		{"all shipments delivered", []Shipment{{Items: first.Items, DeliveredAt: &delivered}, {Items: second.Items, DeliveredAt: &delivered}}, OrderStatusDelivered},
+	}
+	for _, tc := range cases {
+		if got := FulfillmentStatus(items, tc.shipments); got != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, got)
+		}
+	}
+
+	whole := []Shipment{{}}
+	if got := FulfillmentStatus(nil, whole); got != OrderStatusShipped {
+		t.Errorf("Expected an order without items to be shipped by a shipment, got %s", got)
+	}
+	whole[0].DeliveredAt = &delivered
+	if got := FulfillmentStatus(nil, whole); got != OrderStatusDelivered {
+		t.Errorf("Expected an order without items to be delivered with its shipment, got %s", got)
+	}
+}
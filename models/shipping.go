@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"go-fiber-api/money"
+	"go-fiber-api/shipping"
+	"time"
+)
+
+// ShippingMethod is a way of delivering orders, priced by one of the shipping calculators.
+// Which of the prices are used depends on the type: flat uses Price; weight uses Price as the
+// base plus PricePerKg for every started kilogram; free_over_threshold uses Price below
+// FreeThreshold and nothing from it on.
+// @Description Shipping method information
+type ShippingMethod struct {
+	ID            uint        `json:"id" gorm:"primaryKey" example:"1"`
+	Code          string      `json:"code" gorm:"uniqueIndex;not null" example:"standard"`
+	Name          string      `json:"name" gorm:"not null" example:"Standard delivery"`
+	Type          string      `json:"type" gorm:"not null" example:"flat"`
+	Price         money.Money `json:"price" gorm:"embedded;embeddedPrefix:price_" swaggertype:"object,string"`
+	PricePerKg    money.Money `json:"price_per_kg" gorm:"embedded;embeddedPrefix:price_per_kg_" swaggertype:"object,string"`
+	FreeThreshold money.Money `json:"free_threshold" gorm:"embedded;embeddedPrefix:free_threshold_" swaggertype:"object,string"`
+	CreatedAt     time.Time   `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt     time.Time   `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// ShippingMethodRequest represents the payload for creating or updating a shipping method.
+// Amounts are in the store currency.
+// @Description Shipping method create/update request payload
+type ShippingMethodRequest struct {
+	Code          string       `json:"code" validate:"required,max=50" example:"standard"`
+	Name          string       `json:"name" validate:"required,max=100" example:"Standard delivery"`
+	Type          string       `json:"type" validate:"required,oneof=flat weight free_over_threshold" example:"flat"`
+	Price         money.Money  `json:"price" swaggertype:"object,string"`
+	PricePerKg    *money.Money `json:"price_per_kg" validate:"required_if=Type weight" swaggertype:"object,string"`
+	FreeThreshold *money.Money `json:"free_threshold" validate:"required_if=Type free_over_threshold" swaggertype:"object,string"`
+}
+
+// ProductWeightRequest represents the payload for setting the shipping weight of a product
+// @Description Product weight request payload
+type ProductWeightRequest struct {
+	WeightGrams *int `json:"weight_grams" validate:"required,min=0" example:"1800"`
+}
+
+// Apply copies the request onto the shipping method, dropping prices its type does not use
+func (r ShippingMethodRequest) Apply(method *ShippingMethod) {
+	method.Code = r.Code
+	method.Name = r.Name
+	method.Type = r.Type
+	method.Price = r.Price
+	method.PricePerKg = money.Money{}
+	method.FreeThreshold = money.Money{}
+	if r.Type == shipping.TypeWeight {
+		method.PricePerKg = *r.PricePerKg
+	}
+	if r.Type == shipping.TypeFreeOverThreshold {
+		method.FreeThreshold = *r.FreeThreshold
+	}
+}
+
+// Calculator returns the shipping calculator the method is configured with
+func (m ShippingMethod) Calculator() (shipping.Calculator, error) {
+	switch m.Type {
+	case shipping.TypeFlat:
+		return shipping.Flat{Price: m.Price}, nil
+	case shipping.TypeWeight:
+		return shipping.Weight{Base: m.Price, PerKilogram: m.PricePerKg}, nil
+	case shipping.TypeFreeOverThreshold:
+		return shipping.FreeOverThreshold{Threshold: m.FreeThreshold, Price: m.Price}, nil
+	default:
+		return nil, fmt.Errorf("unknown shipping calculator %q", m.Type)
+	}
+}
+
+// ConvertPrices converts the method's prices for display
+func (m *ShippingMethod) ConvertPrices(rate money.Rate) {
+	m.Price = rate.Convert(m.Price)
+	m.PricePerKg = rate.Convert(m.PricePerKg)
+	m.FreeThreshold = rate.Convert(m.FreeThreshold)
+}
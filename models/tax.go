@@ -0,0 +1,84 @@
+package models
+
+import (
+	"go-fiber-api/money"
+	"go-fiber-api/tax"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaxRule is a tax charged on orders shipped to a country, optionally narrowed to one region
+// and to a product category including its subcategories
+// @Description Tax rule information
+type TaxRule struct {
+	ID         uint   `json:"id" gorm:"primaryKey" example:"1"`
+	Name       string `json:"name" gorm:"not null" example:"VAT"`
+	Country    string `json:"country" gorm:"type:varchar(2);not null;index" example:"DE"`
+	Region     string `json:"region" gorm:"not null;default:''" example:""`
+	CategoryID *uint  `json:"category_id" gorm:"index" example:"1"`
+	// Rate is a percentage
+	Rate      string    `json:"rate" gorm:"type:numeric(9,6);not null" example:"19"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// TaxRuleRequest represents the payload for creating or updating a tax rule
+// @Description Tax rule create/update request payload
+type TaxRuleRequest struct {
+	Name       string `json:"name" validate:"required,max=50" example:"VAT"`
+	Country    string `json:"country" validate:"required,iso3166_1_alpha2" example:"DE"`
+	Region     string `json:"region" validate:"max=100" example:"BY"`
+	CategoryID *uint  `json:"category_id" example:"1"`
+	Rate       string `json:"rate" validate:"required" example:"19"`
+}
+
+// OrderTaxLine is the tax collected on an order under one rule name and rate
+// @Description Order tax line
+type OrderTaxLine struct {
+	ID      uint        `json:"-" gorm:"primaryKey"`
+	OrderID uint        `json:"-" gorm:"not null;index"`
+	Name    string      `json:"name" gorm:"not null" example:"VAT"`
+	Rate    string      `json:"rate" gorm:"type:numeric(9,6);not null" example:"19"`
+	Amount  money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_" swaggertype:"object,string"`
+}
+
+// TaxRule returns the rule for the tax calculator
+func (r TaxRule) TaxRule() (tax.Rule, error) {
+	rate, err := tax.ParseRate(r.Rate)
+	if err != nil {
+		return tax.Rule{}, err
+	}
+	return tax.Rule{Name: r.Name, Country: r.Country, Region: r.Region, CategoryID: r.CategoryID, Rate: rate}, nil
+}
+
+// ApplyTax records a calculated breakdown on the order: the total becomes the grand total
+func (o *Order) ApplyTax(breakdown tax.Breakdown, mode string) {
+	o.TaxMode = mode
+	o.Subtotal = breakdown.Subtotal
+	o.TaxTotal = breakdown.Tax
+	o.Total = breakdown.Total
+	o.TaxLines = make([]OrderTaxLine, 0, len(breakdown.Lines))
+	for _, line := range breakdown.Lines {
+		o.TaxLines = append(o.TaxLines, OrderTaxLine{Name: line.Name, Rate: tax.FormatRate(line.Rate), Amount: line.Amount})
+	}
+}
+
+// AfterFind drops the trailing zeros the database pads rates with
+func (r *TaxRule) AfterFind(tx *gorm.DB) error {
+	r.Rate = normalizeTaxRate(r.Rate)
+	return nil
+}
+
+// AfterFind drops the trailing zeros the database pads rates with
+func (l *OrderTaxLine) AfterFind(tx *gorm.DB) error {
+	l.Rate = normalizeTaxRate(l.Rate)
+	return nil
+}
+
+func normalizeTaxRate(rate string) string {
+	if value, err := tax.ParseRate(rate); err == nil {
+		return tax.FormatRate(value)
+	}
+	return rate
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// LoginThrottle tracks failed login attempts for one account or client IP
+type LoginThrottle struct {
+	ID            uint   `gorm:"primaryKey"`
+	Key           string `gorm:"not null;uniqueIndex"`
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Expired reports whether the failures are old enough to be forgotten: the window has passed
+// since the last failure or, for a lockout that outlasts the window, since the lockout ended.
+// Otherwise a long lockout would end with the count already reset.
+func (t LoginThrottle) Expired(now time.Time, window time.Duration) bool {
+	since := t.LastFailureAt
+	if t.LockedUntil != nil && t.LockedUntil.After(since) {
+		since = *t.LockedUntil
+	}
+	return now.Sub(since) > window
+}
+
+// LoginThrottleKeyForEmail returns the throttle key tracking attempts against an account
+func LoginThrottleKeyForEmail(email string) string {
+	return "email:" + email
+}
+
+// LoginThrottleKeyForIP returns the throttle key tracking attempts from a client IP
+func LoginThrottleKeyForIP(ip string) string {
+	return "ip:" + ip
+}
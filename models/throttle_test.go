@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginThrottleExpired(t *testing.T) {
+	now := time.Now()
+	window := 15 * time.Minute
+
+	if !(LoginThrottle{LastFailureAt: now.Add(-20 * time.Minute)}).Expired(now, window) {
+		t.Error("Expected failures older than the window to expire")
+	}
+	if (LoginThrottle{LastFailureAt: now.Add(-10 * time.Minute)}).Expired(now, window) {
+		t.Error("Expected recent failures to be kept")
+	}
+
+	// An hour-long lockout that ended 5 minutes ago still counts its failures
+	lockedUntil := now.Add(-5 * time.Minute)
+	locked := LoginThrottle{LastFailureAt: now.Add(-65 * time.Minute), LockedUntil: &lockedUntil}
+	if locked.Expired(now, window) {
+		t.Error("Expected the window to be measured from the end of the lockout")
+	}
+	if !locked.Expired(now.Add(11*time.Minute), window) {
+		t.Error("Expected failures to expire a window after the lockout ended")
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// RecoveryCode is a one-time code that replaces the authenticator app when it is lost
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TwoFactorSetupResponse contains what an authenticator app needs to enroll
+// @Description Two-factor enrollment details
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/Go%20Fiber%20API:user@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Go%20Fiber%20API"`
+	QRCodePNG  string `json:"qr_code_png" example:"iVBORw0KGgoAAAANSUhEUgAA..."`
+}
+
+// TwoFactorCodeRequest carries a code from the authenticator app
+// @Description Two-factor code request payload
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+// DisableTwoFactorRequest carries the current password and a code from the authenticator app
+// @Description Two-factor disable request payload
+type DisableTwoFactorRequest struct {
+	Code            string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+	CurrentPassword string `json:"current_password" validate:"required" example:"password123"`
+}
+
+// RecoveryCodesResponse lists freshly generated recovery codes, which are only shown once
+// @Description Two-factor recovery codes
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"7KQ4M-2XH9P,R8T3N-6WJ2L"`
+}
+
+// MFAChallengeResponse is returned by login instead of a token when two-factor authentication is enabled
+// @Description Two-factor login challenge
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required" example:"true"`
+	MFAToken    string `json:"mfa_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+// MFALoginRequest completes a two-factor login with an authenticator or recovery code
+// @Description Two-factor login request payload
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric" example:"123456"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code,omitempty,max=20" example:"7KQ4M-2XH9P"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"go-fiber-api/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,31 +10,41 @@ import (
 // User represents a user in the system
 // @Description User account information
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey" example:"1"`
-	Email     string         `json:"email" gorm:"unique;not null" example:"user@example.com"`
-	Password  string         `json:"-" gorm:"not null"`
-	FirstName string         `json:"first_name" example:"John"`
-	LastName  string         `json:"last_name" example:"Doe"`
-	Role      string         `json:"role" gorm:"default:user" example:"user"`
-	CreatedAt time.Time      `json:"created_at" example:"2023-01-01T00:00:00Z"`
-	UpdatedAt time.Time      `json:"updated_at" example:"2023-01-01T00:00:00Z"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
-	Orders    []Order        `json:"orders,omitempty" gorm:"foreignKey:UserID"`
+	ID               uint           `json:"id" gorm:"primaryKey" example:"1"`
+	Email            string         `json:"email" gorm:"unique;not null" example:"user@example.com"`
+	Password         string         `json:"-" gorm:"not null"`
+	FirstName        string         `json:"first_name" example:"John"`
+	LastName         string         `json:"last_name" example:"Doe"`
+	Role             string         `json:"role" gorm:"default:user" example:"user"`
+	EmailVerifiedAt  *time.Time     `json:"email_verified_at" example:"2023-01-01T00:00:00Z"`
+	TwoFactorEnabled bool           `json:"two_factor_enabled" gorm:"not null;default:false" example:"false"`
+	TOTPSecret       string         `json:"-"`
+	TOTPLastStep     int64          `json:"-"`
+	CreatedAt        time.Time      `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt        time.Time      `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+	Orders           []Order        `json:"orders,omitempty" gorm:"foreignKey:UserID"`
 }
 
 // Product represents a product in the system
 // @Description Product information
 type Product struct {
-	ID          uint           `json:"id" gorm:"primaryKey" example:"1"`
-	Name        string         `json:"name" gorm:"not null" example:"Laptop"`
-	Description string         `json:"description" example:"High-performance laptop"`
-	Price       float64        `json:"price" gorm:"not null" example:"999.99"`
-	Stock       int            `json:"stock" gorm:"default:0" example:"10"`
-	CategoryID  uint           `json:"category_id" example:"1"`
-	Category    Category       `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
-	CreatedAt   time.Time      `json:"created_at" example:"2023-01-01T00:00:00Z"`
-	UpdatedAt   time.Time      `json:"updated_at" example:"2023-01-01T00:00:00Z"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID            uint             `json:"id" gorm:"primaryKey" example:"1"`
+	Name          string           `json:"name" gorm:"not null" example:"Laptop"`
+	Description   string           `json:"description" example:"High-performance laptop"`
+	Price         money.Money      `json:"price" gorm:"embedded;embeddedPrefix:price_" swaggertype:"object,string"`
+	Stock         int              `json:"stock" gorm:"default:0" example:"10"`
+	WeightGrams   int              `json:"weight_grams" gorm:"not null;default:0" example:"1800"`
+	CategoryID    uint             `json:"category_id" example:"1"`
+	Category      Category         `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	AverageRating float64          `json:"average_rating" gorm:"not null;default:0;index" example:"4.5"`
+	ReviewCount   int              `json:"review_count" gorm:"not null;default:0" example:"12"`
+	Images        []ProductImage   `json:"images,omitempty" gorm:"foreignKey:ProductID"`
+	Options       []ProductOption  `json:"options,omitempty" gorm:"foreignKey:ProductID"`
+	Variants      []ProductVariant `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+	CreatedAt     time.Time        `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt     time.Time        `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+	DeletedAt     gorm.DeletedAt   `json:"-" gorm:"index"`
 }
 
 // Category represents a product category
@@ -41,51 +52,64 @@ type Product struct {
 type Category struct {
 	ID        uint           `json:"id" gorm:"primaryKey" example:"1"`
 	Name      string         `json:"name" gorm:"unique;not null" example:"Electronics"`
+	Slug      string         `json:"slug" gorm:"uniqueIndex" example:"electronics"`
+	ParentID  *uint          `json:"parent_id" gorm:"index" example:"1"`
 	CreatedAt time.Time      `json:"created_at" example:"2023-01-01T00:00:00Z"`
 	UpdatedAt time.Time      `json:"updated_at" example:"2023-01-01T00:00:00Z"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 	Products  []Product      `json:"products,omitempty" gorm:"foreignKey:CategoryID"`
+	Children  []Category     `json:"children,omitempty" gorm:"foreignKey:ParentID"`
 }
 
 // Order represents a user order
 // @Description Order information
 type Order struct {
-	ID        uint           `json:"id" gorm:"primaryKey" example:"1"`
-	UserID    uint           `json:"user_id" gorm:"not null" example:"1"`
-	User      User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	Total     float64        `json:"total" gorm:"not null" example:"99.99"`
-	Status    string         `json:"status" gorm:"default:pending" example:"pending"`
-	CreatedAt time.Time      `json:"created_at" example:"2023-01-01T00:00:00Z"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
-}
-
-// LoginRequest represents login request payload
-type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
-}
-
-// RegisterRequest represents registration request payload
-type RegisterRequest struct {
-	Email     string `json:"email" validate:"required,email"`
-	Password  string `json:"password" validate:"required,min=6"`
-	FirstName string `json:"first_name" validate:"required"`
-	LastName  string `json:"last_name" validate:"required"`
-}
-
-func (u *User) CreateUser(db *gorm.DB) error {
-	return db.Create(u).Error
+	ID     uint        `json:"id" gorm:"primaryKey" example:"1"`
+	UserID uint        `json:"user_id" gorm:"not null" example:"1"`
+	User   User        `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Total  money.Money `json:"total" gorm:"embedded;embeddedPrefix:total_" swaggertype:"object,string"`
+	Status string      `json:"status" gorm:"default:pending" example:"pending"`
+	Items  []OrderItem `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+	// Subtotal excludes tax and shipping and Total includes them; TaxMode tells whether item prices included tax
+	Subtotal money.Money    `json:"subtotal" gorm:"embedded;embeddedPrefix:subtotal_" swaggertype:"object,string"`
+	TaxTotal money.Money    `json:"tax_total" gorm:"embedded;embeddedPrefix:tax_total_" swaggertype:"object,string"`
+	TaxMode  string         `json:"tax_mode" gorm:"not null;default:exclusive" example:"exclusive"`
+	TaxLines []OrderTaxLine `json:"tax_lines,omitempty" gorm:"foreignKey:OrderID"`
+	// ShippingAddress and BillingAddress are copies of address book entries
+	ShippingAddress Address     `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	BillingAddress  Address     `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`
+	ShippingMethod  string      `json:"shipping_method" gorm:"not null;default:''" example:"standard"`
+	ShippingCost    money.Money `json:"shipping_cost" gorm:"embedded;embeddedPrefix:shipping_cost_" swaggertype:"object,string"`
+	// RefundedTotal sums the refunds recorded against the order, for cancellation or returns
+	RefundedTotal money.Money `json:"refunded_total" gorm:"embedded;embeddedPrefix:refunded_total_" swaggertype:"object,string"`
+	// Currency, ExchangeRate and RoundingIncrement snapshot the currency the customer
+	// ordered in and its exchange rate from the store currency at the time
+	Currency          string         `json:"currency" gorm:"type:varchar(3);not null;default:''" example:"EUR"`
+	ExchangeRate      string         `json:"exchange_rate" gorm:"type:numeric(20,10);not null;default:1" example:"0.9215"`
+	RoundingIncrement int64          `json:"rounding_increment" gorm:"not null;default:1" example:"1"`
+	CreatedAt         time.Time      `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
-func GetUserByID(db *gorm.DB, id uint) (*User, error) {
-	var user User
-	err := db.First(&user, id).Error
-	return &user, err
+// OrderItem represents a single product line of an order
+// @Description Order line item information
+type OrderItem struct {
+	ID        uint        `json:"id" gorm:"primaryKey" example:"1"`
+	OrderID   uint        `json:"order_id" gorm:"not null;index" example:"1"`
+	ProductID uint        `json:"product_id" gorm:"not null;index" example:"1"`
+	Product   Product     `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	VariantID *uint       `json:"variant_id,omitempty" gorm:"index" example:"4"`
+	SKU       string      `json:"sku,omitempty" example:"TSHIRT-BLK-M"`
+	Quantity  int         `json:"quantity" gorm:"not null" example:"2"`
+	Price     money.Money `json:"price" gorm:"embedded;embeddedPrefix:price_" swaggertype:"object,string"`
+	// WeightGrams is the weight of one unit when the order was placed
+	WeightGrams int       `json:"weight_grams" gorm:"not null;default:0" example:"1800"`
+	CreatedAt   time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
 }
 
-func GetUserByUsername(db *gorm.DB, username string) (*User, error) {
-	var user User
-	err := db.Where("username = ?", username).First(&user).Error
-	return &user, err
+// IsEmailVerified reports whether the user has confirmed ownership of their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
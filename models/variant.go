@@ -0,0 +1,51 @@
+package models
+
+import (
+	"go-fiber-api/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductOption represents a configurable attribute of a product such as size or color
+// @Description Product option with its possible values
+type ProductOption struct {
+	ID        uint                 `json:"id" gorm:"primaryKey" example:"1"`
+	ProductID uint                 `json:"product_id" gorm:"not null;index" example:"3"`
+	Name      string               `json:"name" gorm:"not null" example:"Size"`
+	Position  int                  `json:"position" example:"1"`
+	Values    []ProductOptionValue `json:"values" gorm:"foreignKey:OptionID"`
+}
+
+// ProductOptionValue represents one possible value of a product option
+// @Description Product option value
+type ProductOptionValue struct {
+	ID       uint   `json:"id" gorm:"primaryKey" example:"1"`
+	OptionID uint   `json:"option_id" gorm:"not null;index" example:"1"`
+	Value    string `json:"value" gorm:"not null" example:"M"`
+	Position int    `json:"position" example:"2"`
+}
+
+// ProductVariant represents a purchasable combination of option values with its own SKU and stock
+// @Description Product variant information
+type ProductVariant struct {
+	ID        uint   `json:"id" gorm:"primaryKey" example:"1"`
+	ProductID uint   `json:"product_id" gorm:"not null;index" example:"3"`
+	SKU       string `json:"sku" gorm:"uniqueIndex;not null" example:"TSHIRT-BLK-M"`
+	// PriceOverride replaces the product price when set; the zero value means no override
+	PriceOverride money.Money          `json:"price_override" gorm:"embedded;embeddedPrefix:price_override_" swaggertype:"object,string"`
+	Price         money.Money          `json:"price" gorm:"-" swaggertype:"object,string"`
+	Stock         int                  `json:"stock" gorm:"default:0" example:"15"`
+	OptionValues  []ProductOptionValue `json:"option_values" gorm:"many2many:product_variant_option_values"`
+	CreatedAt     time.Time            `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt     time.Time            `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+	DeletedAt     gorm.DeletedAt       `json:"-" gorm:"index"`
+}
+
+// EffectivePrice returns the variant's price override, or the product price when there is none
+func (v *ProductVariant) EffectivePrice(productPrice money.Money) money.Money {
+	if !v.PriceOverride.IsZero() {
+		return v.PriceOverride
+	}
+	return productPrice
+}
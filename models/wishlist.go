@@ -0,0 +1,60 @@
+package models
+
+import (
+	"go-fiber-api/money"
+	"time"
+)
+
+// WishlistItem represents a product saved to a user's wishlist
+// @Description Wishlist entry information
+type WishlistItem struct {
+	ID               uint        `json:"id" gorm:"primaryKey" example:"1"`
+	UserID           uint        `json:"user_id" gorm:"not null;uniqueIndex:idx_wishlist_user_product" example:"1"`
+	ProductID        uint        `json:"product_id" gorm:"not null;uniqueIndex:idx_wishlist_user_product" example:"1"`
+	Product          Product     `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	PriceWhenAdded   money.Money `json:"price_when_added" gorm:"embedded;embeddedPrefix:price_when_added_" swaggertype:"object,string"`
+	InStockWhenAdded bool        `json:"in_stock_when_added" example:"true"`
+	CreatedAt        time.Time   `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// AddWishlistItemRequest represents the payload for adding a product to the wishlist
+// @Description Wishlist add request payload
+type AddWishlistItemRequest struct {
+	ProductID uint `json:"product_id" validate:"required" example:"1"`
+}
+
+// WishlistItemResponse represents a wishlist entry with flags comparing the
+// product's current state to the state it had when it was saved. Products removed
+// from the catalog stay listed but are flagged unavailable.
+// @Description Wishlist entry with stock and price-drop flags
+type WishlistItemResponse struct {
+	WishlistItem
+	CurrentPrice money.Money `json:"current_price" swaggertype:"object,string"`
+	OutOfStock   bool        `json:"out_of_stock" example:"false"`
+	BackInStock  bool        `json:"back_in_stock" example:"false"`
+	PriceDropped bool        `json:"price_dropped" example:"true"`
+	Unavailable  bool        `json:"unavailable" example:"false"`
+}
+
+// NewWishlistItemResponse compares a wishlist entry against its product's current price and stock.
+// The product must be loaded including soft-deleted ones; those can no longer be bought, so they
+// are flagged unavailable and out of stock rather than compared.
+func NewWishlistItemResponse(item WishlistItem) WishlistItemResponse {
+	if item.Product.ID == 0 || item.Product.DeletedAt.Valid {
+		return WishlistItemResponse{
+			WishlistItem: item,
+			CurrentPrice: item.Product.Price,
+			OutOfStock:   true,
+			Unavailable:  true,
+		}
+	}
+
+	inStock := item.Product.Stock > 0
+	return WishlistItemResponse{
+		WishlistItem: item,
+		CurrentPrice: item.Product.Price,
+		OutOfStock:   !inStock,
+		BackInStock:  inStock && !item.InStockWhenAdded,
+		PriceDropped: item.Product.Price.Currency == item.PriceWhenAdded.Currency && item.Product.Price.Cmp(item.PriceWhenAdded) < 0,
+	}
+}
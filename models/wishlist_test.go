@@ -0,0 +1,29 @@
+package models
+
+import (
+	"go-fiber-api/money"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestWishlistItemResponseFlagsRemovedProducts(t *testing.T) {
+	usd := func(amount string) money.Money { return money.MustParse(amount, "USD") }
+	item := WishlistItem{
+		ProductID:      1,
+		Product:        Product{ID: 1, Price: usd("8.00"), Stock: 5},
+		PriceWhenAdded: usd("10.00"),
+	}
+
+	response := NewWishlistItemResponse(item)
+	if response.Unavailable || !response.BackInStock || !response.PriceDropped {
+		t.Errorf("Expected an available product back in stock and cheaper, got %+v", response)
+	}
+
+	item.Product.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	response = NewWishlistItemResponse(item)
+	if !response.Unavailable || !response.OutOfStock || response.BackInStock || response.PriceDropped {
+		t.Errorf("Expected a removed product to be unavailable and out of stock, got %+v", response)
+	}
+}
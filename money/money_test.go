@@ -0,0 +1,95 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAndDecimal(t *testing.T) {
+	cases := []struct {
+		amount   string
+		currency string
+		minor    int64
+		decimal  string
+	}{
+		{"999.99", "USD", 99999, "999.99"},
+		{"0.5", "usd", 50, "0.50"},
+		{"-1.05", "EUR", -105, "-1.05"},
+		{"7", "EUR", 700, "7.00"},
+		{"1500", "JPY", 1500, "1500"},
+		{"1.234", "KWD", 1234, "1.234"},
+		{"0.01", "USD", 1, "0.01"},
+	}
+	for _, tc := range cases {
+		m, err := Parse(tc.amount, tc.currency)
+		if err != nil {
+			t.Fatalf("Parse(%q, %q) failed: %v", tc.amount, tc.currency, err)
+		}
+		if m.Amount != tc.minor || m.Decimal() != tc.decimal {
+			t.Errorf("Parse(%q, %q) = %d %q, expected %d %q", tc.amount, tc.currency, m.Amount, m.Decimal(), tc.minor, tc.decimal)
+		}
+	}
+}
+
+func TestParseRejectsInvalidAmounts(t *testing.T) {
+	for _, amount := range []string{"", "abc", "1.999", "1.", ".5", "1e3", "1,00", "--1"} {
+		if _, err := Parse(amount, "USD"); err == nil {
+			t.Errorf("Expected %q to be rejected", amount)
+		}
+	}
+	if _, err := Parse("1.5", "JPY"); err != ErrInvalidAmount {
+		t.Errorf("Expected fractional yen to be rejected, got %v", err)
+	}
+	if _, err := Parse("1", "DOLLAR"); err != ErrInvalidCurrency {
+		t.Errorf("Expected invalid currency, got %v", err)
+	}
+}
+
+func TestArithmeticIsExact(t *testing.T) {
+	total := MustParse("99.99", "USD").Add(MustParse("149.99", "USD"))
+	if total.Decimal() != "249.98" {
+		t.Errorf("Expected 249.98, got %s", total.Decimal())
+	}
+	if got := MustParse("0.10", "USD").Mul(3); got.Decimal() != "0.30" {
+		t.Errorf("Expected 0.30, got %s", got.Decimal())
+	}
+	if MustParse("1", "USD").Cmp(MustParse("2", "USD")) != -1 {
+		t.Error("Expected 1 USD to be less than 2 USD")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected adding different currencies to panic")
+		}
+	}()
+	MustParse("1", "USD").Add(MustParse("1", "EUR"))
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Price    Money `json:"price"`
+		Override Money `json:"override"`
+	}{Price: MustParse("24.99", "EUR")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"price":{"amount":"24.99","currency":"EUR"},"override":null}` {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+
+	var v struct {
+		A Money `json:"a"`
+		B Money `json:"b"`
+		C Money `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":{"amount":"24.99","currency":"EUR"},"b":{"amount":12.5,"currency":"usd"},"c":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != New(2499, "EUR") || v.B != New(1250, "USD") || !v.C.IsZero() {
+		t.Errorf("Unexpected values %+v", v)
+	}
+
+	if err := json.Unmarshal([]byte(`{"a":{"amount":"0.001","currency":"USD"}}`), &v); err == nil {
+		t.Error("Expected too precise amount to be rejected")
+	}
+}
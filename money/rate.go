@@ -0,0 +1,109 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidRate is returned for exchange rates that are not positive decimal numbers
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// rateDecimals is the precision rates are stored and formatted with
+const rateDecimals = 10
+
+// Rate converts amounts from one currency to another
+type Rate struct {
+	From string
+	To   string
+	// Value is the number of units of To for one unit of From
+	Value *big.Rat
+	// Increment is the multiple of minor units of To that converted amounts are rounded to,
+	// e.g. 5 for Swiss francs rounded to 0.05. Zero means 1.
+	Increment int64
+}
+
+// Identity returns the rate that converts currency to itself
+func Identity(currency string) Rate {
+	return Rate{From: currency, To: currency, Value: big.NewRat(1, 1), Increment: 1}
+}
+
+// ParseRate reads a positive decimal exchange rate such as "0.9215"
+func ParseRate(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+		if fraction == "" {
+			return nil, ErrInvalidRate
+		}
+	}
+	if whole == "" || len(fraction) > rateDecimals || !digits(whole) || !digits(fraction) {
+		return nil, ErrInvalidRate
+	}
+
+	value, ok := new(big.Rat).SetString(s)
+	if !ok || value.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return value, nil
+}
+
+// FormatRate formats a rate as a decimal without trailing zeros, e.g. "0.9215"
+func FormatRate(value *big.Rat) string {
+	s := value.FloatString(rateDecimals)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Convert returns m in the rate's target currency, rounded half away from zero to the rate's
+// increment. Amounts that are not in the rate's source currency, including the zero value, are
+// returned unchanged: they keep their own currency label rather than being converted wrongly.
+func (r Rate) Convert(m Money) Money {
+	if m.Currency != r.From || r.Value == nil {
+		return m
+	}
+	if r.From == r.To && r.Value.Cmp(big.NewRat(1, 1)) == 0 && r.Increment <= 1 {
+		return m
+	}
+
+	increment := r.Increment
+	if increment < 1 {
+		increment = 1
+	}
+
+	// minor units of To = minor units of From * rate * 10^(exp(To) - exp(From)), in increments
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, r.Value)
+	v.Mul(v, new(big.Rat).SetFrac(pow10(Exponent(r.To)), pow10(Exponent(r.From))))
+	v.Quo(v, new(big.Rat).SetInt64(increment))
+
+	steps := FromRat(v, r.To)
+	return steps.Mul(increment)
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func roundHalfAwayFromZero(v *big.Rat) *big.Int {
+	num := new(big.Int).Abs(v.Num())
+	q, rem := new(big.Int).QuoRem(num, v.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(v.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
+
+// FromRat returns a fractional number of minor units of currency, rounded half away from zero.
+// Calculations such as tax shares keep exact fractions and round once at the end.
+func FromRat(minor *big.Rat, currency string) Money {
+	rounded := roundHalfAwayFromZero(minor)
+	if !rounded.IsInt64() {
+		panic("money: amount overflows")
+	}
+	return New(rounded.Int64(), currency)
+}
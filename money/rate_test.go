@@ -0,0 +1,68 @@
+package money
+
+import "testing"
+
+func TestConvertRoundsPerCurrency(t *testing.T) {
+	cases := []struct {
+		amount    string
+		rate      string
+		to        string
+		increment int64
+		expected  string
+	}{
+		{"999.99", "0.92", "EUR", 1, "919.99"},
+		{"10.00", "0.925", "EUR", 1, "9.25"},
+		{"0.01", "0.5", "EUR", 1, "0.01"},
+		{"999.99", "151.37", "JPY", 1, "151368"},
+		{"19.99", "0.8812", "CHF", 5, "17.60"},
+		{"-10.00", "0.333", "GBP", 1, "-3.33"},
+		{"100.00", "0.3077", "KWD", 1, "30.770"},
+	}
+	for _, tc := range cases {
+		value, err := ParseRate(tc.rate)
+		if err != nil {
+			t.Fatalf("ParseRate(%q) failed: %v", tc.rate, err)
+		}
+		rate := Rate{From: "USD", To: tc.to, Value: value, Increment: tc.increment}
+		got := rate.Convert(MustParse(tc.amount, "USD"))
+		if got.Currency != tc.to || got.Decimal() != tc.expected {
+			t.Errorf("Converting %s USD at %s: expected %s %s, got %s", tc.amount, tc.rate, tc.expected, tc.to, got)
+		}
+	}
+}
+
+func TestConvertLeavesOtherCurrenciesAlone(t *testing.T) {
+	value, _ := ParseRate("0.92")
+	rate := Rate{From: "USD", To: "EUR", Value: value}
+
+	if got := rate.Convert(Money{}); !got.IsZero() {
+		t.Errorf("Expected the zero value to stay unset, got %s", got)
+	}
+	gbp := MustParse("5.00", "GBP")
+	if got := rate.Convert(gbp); got != gbp {
+		t.Errorf("Expected an amount in another currency to be unchanged, got %s", got)
+	}
+	usd := MustParse("5.00", "USD")
+	if got := Identity("USD").Convert(usd); got != usd {
+		t.Errorf("Expected the identity rate to keep the amount, got %s", got)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, s := range []string{"", "0", "0.0", "-1", "1.", ".5", "1/3", "1e3", "1.12345678901"} {
+		if _, err := ParseRate(s); err == nil {
+			t.Errorf("Expected rate %q to be rejected", s)
+		}
+	}
+
+	value, err := ParseRate("0.921500")
+	if err != nil {
+		t.Fatalf("ParseRate failed: %v", err)
+	}
+	if FormatRate(value) != "0.9215" {
+		t.Errorf("Expected 0.9215, got %s", FormatRate(value))
+	}
+	if value, _ := ParseRate("151"); FormatRate(value) != "151" {
+		t.Errorf("Expected 151, got %s", FormatRate(value))
+	}
+}
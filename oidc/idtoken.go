@@ -0,0 +1,126 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-fiber-api/tokens"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// keyRefreshInterval is the minimum time between JWKS fetches triggered by an unknown kid
+	keyRefreshInterval = time.Minute
+	// clockSkew is tolerated when checking the time-based claims of ID tokens
+	clockSkew = time.Minute
+)
+
+// IDTokenClaims are the claims of a verified ID token used for signing in
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified Bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Name          string `json:"name"`
+}
+
+// Bool accepts JSON booleans as well as the "true"/"false" strings some providers send
+type Bool bool
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*b = Bool(v)
+	case string:
+		*b = v == "true"
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// VerifyIDToken checks the ID token's signature against the provider's published keys and
+// validates issuer, audience, expiry and nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*IDTokenClaims, error) {
+	claims := &IDTokenClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(ctx, kid)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}), jwt.WithoutClaimsValidation())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIDTokenInvalid, err)
+	}
+
+	now := time.Now()
+	switch {
+	case claims.Issuer != p.Issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrIDTokenInvalid, claims.Issuer)
+	case !claims.VerifyAudience(p.ClientID, true):
+		return nil, fmt.Errorf("%w: not issued for this client", ErrIDTokenInvalid)
+	case claims.ExpiresAt == nil || now.After(claims.ExpiresAt.Add(clockSkew)):
+		return nil, fmt.Errorf("%w: expired", ErrIDTokenInvalid)
+	case claims.IssuedAt != nil && now.Add(clockSkew).Before(claims.IssuedAt.Time):
+		return nil, fmt.Errorf("%w: issued in the future", ErrIDTokenInvalid)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", ErrIDTokenInvalid)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrIDTokenInvalid)
+	}
+	return claims, nil
+}
+
+// publicKey returns the provider key named kid, refetching the JWKS when the key is unknown
+// since providers rotate their keys
+func (p *Provider) publicKey(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	cache := p.keys
+	p.mu.Unlock()
+
+	if cache != nil {
+		if key, ok := cache.keys[kid]; ok {
+			return key, nil
+		}
+		if time.Since(cache.fetchedAt) < keyRefreshInterval {
+			return nil, fmt.Errorf("oidc: unknown key %q", kid)
+		}
+	}
+
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var jwks tokens.JWKS
+	if err := p.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+
+	cache = &keyCache{keys: map[string]interface{}{}, fetchedAt: time.Now()}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped; tokens signed with them fail verification
+		if key, err := jwk.PublicKey(); err == nil {
+			cache.keys[jwk.Kid] = key.Public
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = cache
+	p.mu.Unlock()
+
+	key, ok := cache.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("oidc: unknown key %q", kid)
+	}
+	return key, nil
+}
@@ -0,0 +1,110 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"go-fiber-api/oidc"
+	"go-fiber-api/oidc/oidctest"
+	"go-fiber-api/tokens"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// signIn runs the full flow and returns the result of verifying the ID token
+func signIn(t *testing.T, m *oidctest.Issuer, p *oidc.Provider, modify func(*oidc.IDTokenClaims)) (*oidc.IDTokenClaims, error) {
+	ctx := context.Background()
+	state, _ := oidc.RandomString()
+	nonce, _ := oidc.RandomString()
+	verifier, _ := oidc.RandomString()
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL returned error: %v", err)
+	}
+	m.SetClaims(nil)
+	code, returnedState := m.Authorize(authURL)
+	if returnedState != state {
+		t.Fatalf("Expected state %q, got %q", state, returnedState)
+	}
+	if modify != nil {
+		modify(&m.Claims)
+	}
+
+	token, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange returned error: %v", err)
+	}
+	return p.VerifyIDToken(ctx, token.IDToken, nonce)
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	m := oidctest.NewIssuer(t)
+
+	claims, err := signIn(t, m, m.Provider(), nil)
+	if err != nil {
+		t.Fatalf("VerifyIDToken returned error: %v", err)
+	}
+	if claims.Subject != "external-42" || claims.Email != "jane@example.com" || !bool(claims.EmailVerified) {
+		t.Errorf("Unexpected claims %+v", claims)
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidClaims(t *testing.T) {
+	m := oidctest.NewIssuer(t)
+
+	tests := []struct {
+		name   string
+		modify func(*oidc.IDTokenClaims)
+	}{
+		{"wrong audience", func(c *oidc.IDTokenClaims) { c.Audience = jwt.ClaimStrings{"other-client"} }},
+		{"wrong issuer", func(c *oidc.IDTokenClaims) { c.Issuer = "https://evil.example.com" }},
+		{"wrong nonce", func(c *oidc.IDTokenClaims) { c.Nonce = "replayed" }},
+		{"expired", func(c *oidc.IDTokenClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour)) }},
+	}
+	for _, tt := range tests {
+		if _, err := signIn(t, m, m.Provider(), tt.modify); !errors.Is(err, oidc.ErrIDTokenInvalid) {
+			t.Errorf("%s: expected oidc.ErrIDTokenInvalid, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestExchangeRequiresPKCEVerifier(t *testing.T) {
+	m := oidctest.NewIssuer(t)
+	p := m.Provider()
+	ctx := context.Background()
+
+	authURL, _ := p.AuthCodeURL(ctx, "state", "nonce", "the-real-verifier-value-which-is-long-enough")
+	m.SetClaims(nil)
+	code, _ := m.Authorize(authURL)
+
+	if _, err := p.Exchange(ctx, code, "a-guessed-verifier"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("Expected invalid_grant for a wrong verifier, got %v", err)
+	}
+}
+
+func TestVerifyIDTokenRejectsUnknownKey(t *testing.T) {
+	m := oidctest.NewIssuer(t)
+	p := m.Provider()
+
+	other, _ := tokens.GenerateEd25519Key()
+	otherKeys, _ := tokens.NewKeySet(other)
+	m.SetClaims(func(c *oidc.IDTokenClaims) { c.Nonce = "n" })
+	forged, _ := otherKeys.Sign(m.Claims)
+
+	if _, err := p.VerifyIDToken(context.Background(), forged, "n"); !errors.Is(err, oidc.ErrIDTokenInvalid) {
+		t.Errorf("Expected oidc.ErrIDTokenInvalid for a token signed by another key, got %v", err)
+	}
+}
+
+func TestDiscoverRejectsMismatchedIssuer(t *testing.T) {
+	m := oidctest.NewIssuer(t)
+	p := m.Provider()
+	p.Issuer = m.URL + "/"
+
+	if _, err := p.Discover(context.Background()); err == nil {
+		t.Error("Expected discovery for a different issuer to fail")
+	}
+}
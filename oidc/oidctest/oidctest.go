@@ -0,0 +1,126 @@
+// Package oidctest provides a minimal OpenID provider for testing sign-in flows
+package oidctest
+
+import (
+	"encoding/json"
+	"go-fiber-api/oidc"
+	"go-fiber-api/tokens"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Issuer is an OpenID provider that issues one authorization code at a time. The ID token
+// it issues carries Claims, which SetClaims resets and Authorize binds to the request's nonce.
+type Issuer struct {
+	URL    string
+	Claims oidc.IDTokenClaims
+
+	t       *testing.T
+	keys    *tokens.KeySet
+	pkceFor map[string]string
+}
+
+// NewIssuer starts an issuer that is shut down when the test ends
+func NewIssuer(t *testing.T) *Issuer {
+	key, err := tokens.GenerateEd25519Key()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key returned error: %v", err)
+	}
+	keys, _ := tokens.NewKeySet(key)
+
+	m := &Issuer{t: t, keys: keys, pkceFor: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidc.Discovery{
+			Issuer:                m.URL,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JWKSURI:               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(m.keys.JWKS())
+	})
+	mux.HandleFunc("/token", m.token)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	m.URL = server.URL
+	return m
+}
+
+// Provider returns a provider named "mock" registered with the issuer
+func (m *Issuer) Provider() *oidc.Provider {
+	return &oidc.Provider{
+		Name:         "mock",
+		Issuer:       m.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"openid", "email"},
+		RedirectURL:  "http://localhost:3000/auth/oidc/mock/callback",
+	}
+}
+
+// Authorize simulates the user signing in at the provider and returns the code and state
+// the provider would redirect back with
+func (m *Issuer) Authorize(authURL string) (code, state string) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatalf("Invalid authorization URL: %v", err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("response_type") != "code" {
+		m.t.Fatalf("Unexpected authorization request %s", u.RawQuery)
+	}
+
+	code = "code-123"
+	m.pkceFor[code] = q.Get("code_challenge")
+	m.Claims.Nonce = q.Get("nonce")
+	return code, q.Get("state")
+}
+
+// SetClaims resets the claims to a valid, verified identity and applies modify
+func (m *Issuer) SetClaims(modify func(*oidc.IDTokenClaims)) {
+	now := time.Now()
+	m.Claims = oidc.IDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.URL,
+			Subject:   "external-42",
+			Audience:  jwt.ClaimStrings{"client"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+		Email:         "jane@example.com",
+		EmailVerified: true,
+	}
+	if modify != nil {
+		modify(&m.Claims)
+	}
+}
+
+func (m *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, _ := r.BasicAuth()
+	if clientID != "client" || secret != "secret" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	code := r.PostFormValue("code")
+	challenge, ok := m.pkceFor[code]
+	if !ok || oidc.PKCEChallenge(r.PostFormValue("code_verifier")) != challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	delete(m.pkceFor, code)
+
+	idToken, err := m.keys.Sign(m.Claims)
+	if err != nil {
+		m.t.Errorf("Sign returned error: %v", err)
+		http.Error(w, `{"error":"server_error"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(oidc.TokenResponse{AccessToken: "access", TokenType: "Bearer", IDToken: idToken})
+}
@@ -0,0 +1,145 @@
+// Package problem defines the typed errors handlers return and renders them as
+// RFC 7807 problem details with a stable, machine-readable code.
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-api/models"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// ContentType is the media type of problem detail responses
+const ContentType = "application/problem+json"
+
+// TypePrefix turns an error code into the problem type URI, e.g. urn:problem-type:order_not_found
+const TypePrefix = "urn:problem-type:"
+
+// RequestIDKey is the Locals key the requestid middleware stores the request ID under
+const RequestIDKey = "requestid"
+
+// Codes shared by several handlers; other codes are declared where they are returned
+const (
+	CodeInvalidInput     = "invalid_input"
+	CodeInvalidID        = "invalid_id"
+	CodeValidationFailed = "validation_failed"
+	CodeInternal         = "internal_error"
+)
+
+// Error is an error with everything needed to render it as a problem detail.
+// Handlers return it and the ErrorHandler writes the response.
+type Error struct {
+	Status int
+	Code   string
+	Detail string
+	Fields []models.FieldError
+	// Err is the underlying cause; it is logged but never sent to clients
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Detail, e.Err)
+	}
+	return e.Code + ": " + e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns a copy of e caused by err
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// New returns an error with the given status, code and human-readable detail
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+func BadRequest(code, detail string) *Error {
+	return New(fiber.StatusBadRequest, code, detail)
+}
+
+func Unauthorized(code, detail string) *Error {
+	return New(fiber.StatusUnauthorized, code, detail)
+}
+
+func Forbidden(code, detail string) *Error {
+	return New(fiber.StatusForbidden, code, detail)
+}
+
+func NotFound(code, detail string) *Error {
+	return New(fiber.StatusNotFound, code, detail)
+}
+
+func Conflict(code, detail string) *Error {
+	return New(fiber.StatusConflict, code, detail)
+}
+
+// Internal reports a server-side failure; every one shares the internal_error code
+func Internal(detail string) *Error {
+	return New(fiber.StatusInternalServerError, CodeInternal, detail)
+}
+
+// Validation reports request fields that failed validation with 422
+func Validation(fields []models.FieldError) *Error {
+	e := New(fiber.StatusUnprocessableEntity, CodeValidationFailed, "Validation failed")
+	e.Fields = fields
+	return e
+}
+
+// From converts any error into a problem. Fiber errors keep their status and
+// message; anything else becomes an internal error without leaking its text.
+func From(err error) *Error {
+	var p *Error
+	if errors.As(err, &p) {
+		return p
+	}
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return New(fe.Code, statusCode(fe.Code), fe.Message)
+	}
+	return Internal("Internal server error").Wrap(err)
+}
+
+// statusCode derives a code from the status text, e.g. request_entity_too_large
+func statusCode(status int) string {
+	text := utils.StatusMessage(status)
+	if text == "" {
+		return CodeInternal
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
+
+// ErrorHandler is the Fiber error handler writing every returned error as problem+json
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	p := From(err)
+	requestID, _ := c.Locals(RequestIDKey).(string)
+	if p.Status >= fiber.StatusInternalServerError {
+		log.Printf("%s %s failed (request %s): %v", c.Method(), c.Path(), requestID, p)
+	}
+
+	body := models.Problem{
+		Type:      TypePrefix + p.Code,
+		Title:     utils.StatusMessage(p.Status),
+		Status:    p.Status,
+		Detail:    p.Detail,
+		Code:      p.Code,
+		Instance:  c.Path(),
+		RequestID: requestID,
+		Errors:    p.Fields,
+	}
+	if err := c.Status(p.Status).JSON(body); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, ContentType)
+	return nil
+}
@@ -0,0 +1,100 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"go-fiber-api/models"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+func serve(t *testing.T, handler fiber.Handler) (int, string, models.Problem) {
+	t.Helper()
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(requestid.New(requestid.Config{ContextKey: RequestIDKey, Generator: func() string { return "req-1" }}))
+	app.Get("/orders/:id", handler)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/orders/42", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body models.Problem
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Could not decode problem: %v", err)
+	}
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), body
+}
+
+func TestErrorHandlerRendersTypedError(t *testing.T) {
+	status, contentType, body := serve(t, func(c *fiber.Ctx) error {
+		return NotFound("order_not_found", "Order not found")
+	})
+
+	if status != fiber.StatusNotFound || contentType != ContentType {
+		t.Fatalf("Expected 404 %s, got %d %s", ContentType, status, contentType)
+	}
+	expected := models.Problem{
+		Type:      "urn:problem-type:order_not_found",
+		Title:     "Not Found",
+		Status:    404,
+		Detail:    "Order not found",
+		Code:      "order_not_found",
+		Instance:  "/orders/42",
+		RequestID: "req-1",
+	}
+	if body.Type != expected.Type || body.Title != expected.Title || body.Status != expected.Status ||
+		body.Detail != expected.Detail || body.Code != expected.Code || body.Instance != expected.Instance ||
+		body.RequestID != expected.RequestID || body.Errors != nil {
+		t.Errorf("Expected %+v, got %+v", expected, body)
+	}
+}
+
+func TestErrorHandlerIncludesFieldErrors(t *testing.T) {
+	status, _, body := serve(t, func(c *fiber.Ctx) error {
+		return Validation([]models.FieldError{{Field: "email", Rule: "email", Message: "must be a valid email address"}})
+	})
+
+	if status != fiber.StatusUnprocessableEntity || body.Code != CodeValidationFailed {
+		t.Fatalf("Expected 422 validation_failed, got %d %s", status, body.Code)
+	}
+	if len(body.Errors) != 1 || body.Errors[0].Field != "email" {
+		t.Errorf("Expected the email field error, got %+v", body.Errors)
+	}
+}
+
+func TestErrorHandlerMapsOtherErrors(t *testing.T) {
+	status, _, body := serve(t, func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, "Request Entity Too Large")
+	})
+	if status != fiber.StatusRequestEntityTooLarge || body.Code != "request_entity_too_large" {
+		t.Errorf("Expected fiber error to keep its status, got %d %s", status, body.Code)
+	}
+
+	status, _, body = serve(t, func(c *fiber.Ctx) error {
+		return errors.New("pq: connection refused")
+	})
+	if status != fiber.StatusInternalServerError || body.Code != CodeInternal || body.Detail != "Internal server error" {
+		t.Errorf("Expected unknown error to be hidden behind internal_error, got %d %+v", status, body)
+	}
+}
+
+func TestWrapKeepsProblemAndCause(t *testing.T) {
+	cause := errors.New("disk full")
+	base := Internal("Failed to store image")
+	err := base.Wrap(cause)
+
+	if !errors.Is(err, cause) {
+		t.Error("Expected the cause to be reachable with errors.Is")
+	}
+	if base.Err != nil {
+		t.Error("Expected Wrap to leave the original error untouched")
+	}
+	if From(err) != err {
+		t.Error("Expected From to return the problem itself")
+	}
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"go-fiber-api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GormIdentityRepository is the IdentityRepository backed by the database
+type GormIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewGormIdentityRepository(db *gorm.DB) *GormIdentityRepository {
+	return &GormIdentityRepository{db: db}
+}
+
+func (r *GormIdentityRepository) WithTx(tx *gorm.DB) IdentityRepository {
+	return &GormIdentityRepository{db: tx}
+}
+
+func (r *GormIdentityRepository) CreateLoginState(state *models.OIDCLoginState) error {
+	return r.db.Create(state).Error
+}
+
+func (r *GormIdentityRepository) ConsumeLoginState(stateHash, provider string) (*models.OIDCLoginState, error) {
+	var state models.OIDCLoginState
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ? AND provider = ?", stateHash, provider).First(&state).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&state).Error
+	})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &state, nil
+}
+
+func (r *GormIdentityRepository) DeleteExpiredLoginStates(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&models.OIDCLoginState{}).Error
+}
+
+func (r *GormIdentityRepository) FindIdentity(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &identity, nil
+}
+
+func (r *GormIdentityRepository) CreateIdentity(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
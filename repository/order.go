@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"go-fiber-api/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormOrderRepository is the OrderRepository backed by the database
+type GormOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewGormOrderRepository(db *gorm.DB) *GormOrderRepository {
+	return &GormOrderRepository{db: db}
+}
+
+func (r *GormOrderRepository) WithTx(tx *gorm.DB) OrderRepository {
+	return &GormOrderRepository{db: tx}
+}
+
+func (r *GormOrderRepository) Create(order *models.Order) error {
+	return r.db.Create(order).Error
+}
+
+func (r *GormOrderRepository) Find(id uint) (*models.Order, error) {
+	var order models.Order
+	if err := r.db.Preload("Items").First(&order, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &order, nil
+}
+
+func (r *GormOrderRepository) FindForUpdate(id uint) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := r.db.Model(&order).Association("Items").Find(&order.Items); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *GormOrderRepository) FindForUser(id, userID uint) (*models.Order, error) {
+	var order models.Order
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&order).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &order, nil
+}
+
+func (r *GormOrderRepository) ListByUser(userID uint) ([]models.Order, error) {
+	orders := []models.Order{}
+	err := r.db.Preload("Items").Preload("TaxLines").Where("user_id = ?", userID).Find(&orders).Error
+	return orders, err
+}
+
+func (r *GormOrderRepository) List(filter OrderFilter, offset, limit int) ([]models.Order, int64, error) {
+	query := r.db.Model(&models.Order{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	orders := []models.Order{}
+	err := query.Preload("Items").Preload("TaxLines").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&orders).Error
+	return orders, total, err
+}
+
+func (r *GormOrderRepository) UpdateStatus(order *models.Order, status string) error {
+	return r.db.Model(order).Update("status", status).Error
+}
+
+func (r *GormOrderRepository) AddRefund(order *models.Order, refund *models.Refund) error {
+	if err := r.db.Create(refund).Error; err != nil {
+		return err
+	}
+	return r.db.Model(order).Updates(map[string]interface{}{
+		"refunded_total_amount":   order.RefundedTotal.Amount,
+		"refunded_total_currency": order.RefundedTotal.Currency,
+	}).Error
+}
+
+func (r *GormOrderRepository) HasDeliveredProduct(userID, productID uint) (bool, error) {
+	var delivered int64
+	err := r.db.Model(&models.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("orders.user_id = ? AND orders.status = ? AND order_items.product_id = ?", userID, models.OrderStatusDelivered, productID).
+		Count(&delivered).Error
+	return delivered > 0, err
+}
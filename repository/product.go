@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"go-fiber-api/models"
+
+	"gorm.io/gorm"
+)
+
+// GormProductRepository is the ProductRepository backed by the database
+type GormProductRepository struct {
+	db *gorm.DB
+}
+
+func NewGormProductRepository(db *gorm.DB) *GormProductRepository {
+	return &GormProductRepository{db: db}
+}
+
+func (r *GormProductRepository) WithTx(tx *gorm.DB) ProductRepository {
+	return &GormProductRepository{db: tx}
+}
+
+// byPosition sorts preloaded images, options and option values by their position
+func byPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
+
+func (r *GormProductRepository) FindByID(id uint) (*models.Product, error) {
+	var product models.Product
+	if err := r.db.Preload("Category").First(&product, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &product, nil
+}
+
+func (r *GormProductRepository) FindDetails(id uint) (*models.Product, error) {
+	var product models.Product
+	if err := r.db.Preload("Category").
+		Preload("Images", byPosition).
+		Preload("Options", byPosition).
+		Preload("Options.Values", byPosition).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Variants.OptionValues").
+		Where("id = ?", id).First(&product).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &product, nil
+}
+
+func (r *GormProductRepository) List(filter ProductFilter) ([]models.Product, error) {
+	query := r.db.Preload("Category").Preload("Images", byPosition)
+	if filter.CategoryIDs != nil {
+		query = query.Where("category_id IN ?", filter.CategoryIDs)
+	}
+	if filter.Order != "" {
+		query = query.Order(filter.Order)
+	}
+
+	products := []models.Product{}
+	err := query.Find(&products).Error
+	return products, err
+}
+
+func (r *GormProductRepository) CountVariants(productID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ProductVariant{}).Where("product_id = ?", productID).Count(&count).Error
+	return count, err
+}
+
+func (r *GormProductRepository) FindVariant(productID, variantID uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	if err := r.db.Where("id = ? AND product_id = ?", variantID, productID).First(&variant).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &variant, nil
+}
+
+func (r *GormProductRepository) ReserveStock(productID uint, quantity int) (bool, error) {
+	result := r.db.Model(&models.Product{}).
+		Where("id = ? AND stock >= ?", productID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *GormProductRepository) ReserveVariantStock(variantID uint, quantity int) (bool, error) {
+	result := r.db.Model(&models.ProductVariant{}).
+		Where("id = ? AND stock >= ?", variantID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *GormProductRepository) ReleaseStock(productID uint, quantity int) error {
+	return r.db.Model(&models.Product{}).Where("id = ?", productID).
+		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+}
+
+func (r *GormProductRepository) ReleaseVariantStock(variantID uint, quantity int) error {
+	return r.db.Model(&models.ProductVariant{}).Where("id = ?", variantID).
+		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+}
+
+func (r *GormProductRepository) UpdateRating(productID uint, average float64, count int) error {
+	return r.db.Model(&models.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"average_rating": average,
+		"review_count":   count,
+	}).Error
+}
+
+func (r *GormProductRepository) UpdateWeight(productID uint, grams int) error {
+	return r.db.Model(&models.Product{}).Where("id = ?", productID).Update("weight_grams", grams).Error
+}
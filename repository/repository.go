@@ -0,0 +1,124 @@
+// Package repository is the data access layer for users, products and orders, plus external
+// identities and transactions. Only these sit behind interfaces that handlers can swap for fakes;
+// supporting tables such as categories, images, sessions, API keys, returns and shipping methods
+// are queried through config.DB directly, so handlers using them still need a database. The GORM
+// implementations are the production ones.
+package repository
+
+import (
+	"errors"
+	"go-fiber-api/models"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when a looked up record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// UserRepository stores user accounts
+type UserRepository interface {
+	// WithTx returns a repository running its queries in the transaction
+	WithTx(tx *gorm.DB) UserRepository
+	FindByID(id uint) (*models.User, error)
+	// FindByEmail matches the address exactly, like the unique index on it
+	FindByEmail(email string) (*models.User, error)
+	// FindByEmailFold matches the address ignoring case
+	FindByEmailFold(email string) (*models.User, error)
+	// EmailInUse matches the address ignoring case and also counts soft-deleted users, which
+	// still hold their address in the unique index
+	EmailInUse(email string) (bool, error)
+	Create(user *models.User) error
+	Save(user *models.User) error
+	Update(id uint, fields map[string]interface{}) error
+	// MarkEmailVerified sets the verification time unless the address was already verified
+	MarkEmailVerified(id uint, at time.Time) error
+	// AdvanceTOTPStep records the last used TOTP step and reports false if it was not newer
+	AdvanceTOTPStep(id uint, step int64) (bool, error)
+}
+
+// ProductFilter selects and sorts products in a listing
+type ProductFilter struct {
+	// CategoryIDs limits the listing to these categories when set
+	CategoryIDs []uint
+	// Order is an SQL order clause such as "price DESC"
+	Order string
+}
+
+// ProductRepository stores products, their variants and stock
+type ProductRepository interface {
+	WithTx(tx *gorm.DB) ProductRepository
+	// FindByID loads the product with its category
+	FindByID(id uint) (*models.Product, error)
+	// FindDetails loads the product with its category, images, options and variants
+	FindDetails(id uint) (*models.Product, error)
+	// List loads products with their category and images
+	List(filter ProductFilter) ([]models.Product, error)
+	CountVariants(productID uint) (int64, error)
+	FindVariant(productID, variantID uint) (*models.ProductVariant, error)
+	// ReserveStock takes quantity from the product stock and reports false if not enough is left
+	ReserveStock(productID uint, quantity int) (bool, error)
+	// ReserveVariantStock takes quantity from the variant stock and reports false if not enough is left
+	ReserveVariantStock(variantID uint, quantity int) (bool, error)
+	// ReleaseStock puts quantity back into the product stock, e.g. for a cancelled order
+	ReleaseStock(productID uint, quantity int) error
+	// ReleaseVariantStock puts quantity back into the variant stock
+	ReleaseVariantStock(variantID uint, quantity int) error
+	UpdateRating(productID uint, average float64, count int) error
+	// UpdateWeight sets the shipping weight of one unit of the product
+	UpdateWeight(productID uint, grams int) error
+}
+
+// OrderFilter selects orders in an admin listing
+type OrderFilter struct {
+	Status string
+}
+
+// OrderRepository stores orders and their items
+type OrderRepository interface {
+	WithTx(tx *gorm.DB) OrderRepository
+	// Create inserts the order together with its items and tax lines
+	Create(order *models.Order) error
+	// Find loads an order with its items
+	Find(id uint) (*models.Order, error)
+	// FindForUpdate loads an order with its items and locks it until the transaction ends
+	FindForUpdate(id uint) (*models.Order, error)
+	// FindForUser loads an order only if it belongs to the user
+	FindForUser(id, userID uint) (*models.Order, error)
+	// ListByUser loads the user's orders with their items and tax lines
+	ListByUser(userID uint) ([]models.Order, error)
+	// List loads one page of orders with their items and tax lines, newest first, and the total number of matches
+	List(filter OrderFilter, offset, limit int) ([]models.Order, int64, error)
+	UpdateStatus(order *models.Order, status string) error
+	// AddRefund records the refund and stores the order's refunded total, which must include it
+	AddRefund(order *models.Order, refund *models.Refund) error
+	// HasDeliveredProduct reports whether the user received the product in a delivered order
+	HasDeliveredProduct(userID, productID uint) (bool, error)
+}
+
+// IdentityRepository stores pending external sign-ins and the external accounts linked to users
+type IdentityRepository interface {
+	WithTx(tx *gorm.DB) IdentityRepository
+	CreateLoginState(state *models.OIDCLoginState) error
+	// ConsumeLoginState loads and deletes a pending sign-in, so its state can be used only once
+	ConsumeLoginState(stateHash, provider string) (*models.OIDCLoginState, error)
+	DeleteExpiredLoginStates(now time.Time) error
+	FindIdentity(provider, subject string) (*models.UserIdentity, error)
+	CreateIdentity(identity *models.UserIdentity) error
+}
+
+// notFound translates GORM's missing record error so callers need not depend on GORM
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// IsUniqueViolation reports whether err is PostgreSQL rejecting a row that breaks a unique
+// constraint, such as when a concurrent request inserted the same record first
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
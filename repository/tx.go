@@ -0,0 +1,22 @@
+package repository
+
+import "gorm.io/gorm"
+
+// TxRunner runs a function in a database transaction. Repositories join the transaction
+// through WithTx; fakes of both let handlers with transactions run without a database.
+type TxRunner interface {
+	Transaction(fn func(tx *gorm.DB) error) error
+}
+
+// GormTxRunner is the TxRunner backed by the database
+type GormTxRunner struct {
+	db *gorm.DB
+}
+
+func NewGormTxRunner(db *gorm.DB) *GormTxRunner {
+	return &GormTxRunner{db: db}
+}
+
+func (r *GormTxRunner) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
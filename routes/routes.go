@@ -3,23 +3,85 @@ package routes
 import (
 	"go-fiber-api/controllers"
 	"go-fiber-api/middleware"
+	"go-fiber-api/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func SetupRoutes(app *fiber.App) {
 	// Public endpoints (no authentication required)
-	app.Get("/api/products", controllers.GetProducts)     // 1. List all products
-	app.Get("/api/products/:id", controllers.GetProduct)  // 2. Get product by ID
-	app.Get("/api/categories", controllers.GetCategories) // 3. List categories
-	app.Post("/auth/register", controllers.Register)      // 4. User registration
-	app.Post("/auth/login", controllers.Login)            // 5. User login
+	app.Get("/api/products", controllers.GetProducts)                        // 1. List all products
+	app.Get("/api/products/:id", controllers.GetProduct)                     // 2. Get product by ID
+	app.Get("/api/products/:id/reviews", controllers.GetProductReviews)      // List product reviews
+	app.Get("/api/shipping-methods", controllers.GetShippingMethods)         // List shipping methods
+	app.Get("/api/categories", controllers.GetCategories)                    // 3. List categories
+	app.Get("/api/categories/:id/products", controllers.GetCategoryProducts) // List products in category subtree
+	app.Post("/auth/register", controllers.Register)                         // 4. User registration
+	app.Post("/auth/login", controllers.Login)                               // 5. User login
+	app.Post("/auth/login/2fa", controllers.LoginTwoFactor)                  // Complete two-factor login
+	app.Get("/auth/oidc/:provider/start", controllers.StartOIDCLogin)        // Redirect to external identity provider
+	app.Get("/auth/oidc/:provider/callback", controllers.OIDCCallback)       // Complete external sign-in
+	app.Get("/.well-known/jwks.json", controllers.GetJWKS)                   // Public keys for verifying tokens
+	app.Get("/auth/verify", controllers.VerifyEmail)                         // Confirm email address
+	app.Post("/auth/forgot-password", controllers.ForgotPassword)            // Request password reset link
+	app.Post("/auth/reset-password", controllers.ResetPassword)              // Reset password with token
+
+	// Admin endpoints (admin user or API key with the route's scope required).
+	// Registered before the protected group so its Bearer-only middleware does not apply.
+	admin := app.Group("/api/admin", middleware.AuthOrAPIKeyMiddleware())
+	admin.Post("/categories", middleware.RequireScope(models.ScopeProductsWrite), controllers.CreateCategory)                         // Create category
+	admin.Put("/categories/:id", middleware.RequireScope(models.ScopeProductsWrite), controllers.UpdateCategory)                      // Update or move category
+	admin.Post("/products/:id/images", middleware.RequireScope(models.ScopeProductsWrite), controllers.UploadProductImage)            // Upload product image
+	admin.Delete("/products/:id/images/:imageId", middleware.RequireScope(models.ScopeProductsWrite), controllers.DeleteProductImage) // Delete product image
+	admin.Put("/products/:id/weight", middleware.RequireScope(models.ScopeProductsWrite), controllers.SetProductWeight)               // Set product shipping weight
+	admin.Get("/exchange-rates", middleware.RequireScope(models.ScopeSettingsRead), controllers.GetExchangeRates)                     // List exchange rates
+	admin.Put("/exchange-rates/:currency", middleware.RequireScope(models.ScopeSettingsWrite), controllers.SetExchangeRate)           // Set exchange rate
+	admin.Delete("/exchange-rates/:currency", middleware.RequireScope(models.ScopeSettingsWrite), controllers.DeleteExchangeRate)     // Delete exchange rate
+	admin.Get("/tax-rules", middleware.RequireScope(models.ScopeSettingsRead), controllers.GetTaxRules)                               // List tax rules
+	admin.Post("/tax-rules", middleware.RequireScope(models.ScopeSettingsWrite), controllers.CreateTaxRule)                           // Create tax rule
+	admin.Put("/tax-rules/:id", middleware.RequireScope(models.ScopeSettingsWrite), controllers.UpdateTaxRule)                        // Update tax rule
+	admin.Delete("/tax-rules/:id", middleware.RequireScope(models.ScopeSettingsWrite), controllers.DeleteTaxRule)                     // Delete tax rule
+	admin.Post("/shipping-methods", middleware.RequireScope(models.ScopeSettingsWrite), controllers.CreateShippingMethod)             // Create shipping method
+	admin.Put("/shipping-methods/:id", middleware.RequireScope(models.ScopeSettingsWrite), controllers.UpdateShippingMethod)          // Update shipping method
+	admin.Delete("/shipping-methods/:id", middleware.RequireScope(models.ScopeSettingsWrite), controllers.DeleteShippingMethod)       // Delete shipping method
+	admin.Post("/users/:id/unlock", middleware.RequireScope(models.ScopeUsersWrite), controllers.UnlockUser)                          // Lift login lockout
+	admin.Get("/orders", middleware.RequireScope(models.ScopeOrdersRead), controllers.GetAllOrders)                                   // List all orders
+	admin.Get("/orders/:id/shipments", middleware.RequireScope(models.ScopeOrdersRead), controllers.GetAdminOrderShipments)           // List order shipments
+	admin.Post("/orders/:id/shipments", middleware.RequireScope(models.ScopeOrdersWrite), controllers.CreateShipment)                 // Record shipment
+	admin.Put("/shipments/:id", middleware.RequireScope(models.ScopeOrdersWrite), controllers.UpdateShipment)                         // Update shipment or record delivery
+	admin.Delete("/shipments/:id", middleware.RequireScope(models.ScopeOrdersWrite), controllers.DeleteShipment)                      // Delete shipment
+	admin.Get("/returns", middleware.RequireScope(models.ScopeOrdersRead), controllers.GetReturns)                                    // List returns
+	admin.Post("/returns/:id/approve", middleware.RequireScope(models.ScopeOrdersWrite), controllers.ApproveReturn)                   // Approve return and refund
+	admin.Post("/returns/:id/reject", middleware.RequireScope(models.ScopeOrdersWrite), controllers.RejectReturn)                     // Reject return
+	admin.Get("/api-keys", middleware.AdminOnly(), controllers.GetAPIKeys)                                                            // List API keys
+	admin.Post("/api-keys", middleware.AdminOnly(), controllers.CreateAPIKey)                                                         // Create API key
+	admin.Delete("/api-keys/:id", middleware.AdminOnly(), controllers.DeleteAPIKey)                                                   // Revoke API key
 
 	// Protected endpoints (authentication required)
 	protected := app.Group("/api", middleware.AuthMiddleware())
-	protected.Get("/profile", controllers.GetProfile)        // 6. Get user profile
-	protected.Put("/profile", controllers.UpdateProfile)     // 7. Update user profile
-	protected.Post("/orders", controllers.CreateOrder)       // 8. Create new order
-	protected.Get("/orders", controllers.GetOrders)          // 9. Get user's orders
-	protected.Delete("/orders/:id", controllers.DeleteOrder) // 10. Cancel order
+	protected.Get("/profile", controllers.GetProfile)                            // 6. Get user profile
+	protected.Put("/profile", controllers.UpdateProfile)                         // 7. Update user profile
+	protected.Post("/profile/verify-email", controllers.ResendVerificationEmail) // Resend verification email
+	protected.Put("/profile/password", controllers.ChangePassword)               // Change password
+	protected.Put("/profile/email", controllers.ChangeEmail)                     // Change email (after re-verification)
+	protected.Get("/profile/addresses", controllers.GetAddresses)                // List address book
+	protected.Post("/profile/addresses", controllers.CreateAddress)              // Add address
+	protected.Put("/profile/addresses/:id", controllers.UpdateAddress)           // Update address
+	protected.Delete("/profile/addresses/:id", controllers.DeleteAddress)        // Delete address
+	protected.Get("/profile/sessions", controllers.GetSessions)                  // List active sessions
+	protected.Delete("/profile/sessions", controllers.DeleteOtherSessions)       // Sign out everywhere else
+	protected.Delete("/profile/sessions/:id", controllers.DeleteSession)         // Sign out one session
+	protected.Post("/profile/2fa/setup", controllers.SetupTwoFactor)             // Start two-factor enrollment
+	protected.Post("/profile/2fa/confirm", controllers.ConfirmTwoFactor)         // Enable two-factor authentication
+	protected.Delete("/profile/2fa", controllers.DisableTwoFactor)               // Disable two-factor authentication
+	protected.Post("/orders", controllers.CreateOrder)                           // 8. Create new order
+	protected.Get("/orders", controllers.GetOrders)                              // 9. Get user's orders
+	protected.Delete("/orders/:id", controllers.DeleteOrder)                     // 10. Cancel order
+	protected.Get("/orders/:id/shipments", controllers.GetOrderShipments)        // Track order shipments
+	protected.Get("/orders/:id/returns", controllers.GetOrderReturns)            // List order returns
+	protected.Post("/orders/:id/returns", controllers.CreateReturn)              // Request return
+	protected.Post("/products/:id/reviews", controllers.CreateProductReview)     // Review a delivered product
+	protected.Get("/wishlist", controllers.GetWishlist)                          // List wishlist
+	protected.Post("/wishlist", controllers.AddWishlistItem)                     // Add product to wishlist
+	protected.Delete("/wishlist/:productId", controllers.RemoveWishlistItem)     // Remove product from wishlist
 }
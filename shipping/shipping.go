@@ -0,0 +1,59 @@
+// Package shipping prices the delivery of an order with interchangeable rate calculators.
+package shipping
+
+import (
+	"go-fiber-api/money"
+)
+
+// Calculator types that shipping methods can be configured with
+const (
+	TypeFlat              = "flat"
+	TypeWeight            = "weight"
+	TypeFreeOverThreshold = "free_over_threshold"
+)
+
+// Parcel is what is being shipped
+type Parcel struct {
+	// Subtotal is the value of the goods, which decides free shipping
+	Subtotal    money.Money
+	WeightGrams int64
+}
+
+// Calculator prices the shipping of a parcel
+type Calculator interface {
+	Cost(parcel Parcel) money.Money
+}
+
+// Flat charges the same price for every parcel
+type Flat struct {
+	Price money.Money
+}
+
+func (f Flat) Cost(parcel Parcel) money.Money {
+	return f.Price
+}
+
+// Weight charges a base price plus a price for every started kilogram
+type Weight struct {
+	Base        money.Money
+	PerKilogram money.Money
+}
+
+func (w Weight) Cost(parcel Parcel) money.Money {
+	kilograms := (parcel.WeightGrams + 999) / 1000
+	return w.Base.Add(w.PerKilogram.Mul(kilograms))
+}
+
+// FreeOverThreshold ships for free once the goods are worth at least Threshold, and charges
+// Price otherwise
+type FreeOverThreshold struct {
+	Threshold money.Money
+	Price     money.Money
+}
+
+func (f FreeOverThreshold) Cost(parcel Parcel) money.Money {
+	if parcel.Subtotal.Currency == f.Threshold.Currency && parcel.Subtotal.Cmp(f.Threshold) >= 0 {
+		return money.New(0, f.Price.Currency)
+	}
+	return f.Price
+}
@@ -0,0 +1,34 @@
+package shipping
+
+import (
+	"go-fiber-api/money"
+	"testing"
+)
+
+func usd(amount string) money.Money {
+	return money.MustParse(amount, "USD")
+}
+
+func TestCalculators(t *testing.T) {
+	parcel := Parcel{Subtotal: usd("80.00"), WeightGrams: 2300}
+
+	cases := []struct {
+		name       string
+		calculator Calculator
+		expected   money.Money
+	}{
+		{"flat", Flat{Price: usd("4.99")}, usd("4.99")},
+		{"weight rounds up to started kilograms", Weight{Base: usd("2.00"), PerKilogram: usd("1.50")}, usd("6.50")},
+		{"below threshold", FreeOverThreshold{Threshold: usd("100.00"), Price: usd("5.00")}, usd("5.00")},
+		{"at threshold", FreeOverThreshold{Threshold: usd("80.00"), Price: usd("5.00")}, usd("0.00")},
+	}
+	for _, tc := range cases {
+		if got := tc.calculator.Cost(parcel); got != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, got)
+		}
+	}
+
+	if got := (Weight{Base: usd("2.00"), PerKilogram: usd("1.50")}).Cost(Parcel{}); got != usd("2.00") {
+		t.Errorf("Expected weightless parcels to cost the base price, got %s", got)
+	}
+}
@@ -0,0 +1,31 @@
+// Package storage abstracts where uploaded files such as product images are kept.
+package storage
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+)
+
+// ErrInvalidKey is returned for object keys that are empty or escape the storage root
+var ErrInvalidKey = errors.New("storage: invalid object key")
+
+// Storage stores objects under slash-separated keys and exposes them by URL
+type Storage interface {
+	// Put stores data under key, replacing any existing object
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete removes the object stored under key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL under which the object can be downloaded
+	URL(key string) string
+}
+
+// cleanKey normalizes an object key and rejects keys that would escape the storage root
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if key == "" || key == "." {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
@@ -0,0 +1,223 @@
+// Package tax calculates the taxes of an order from rules by destination and product category.
+package tax
+
+import (
+	"errors"
+	"go-fiber-api/money"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Pricing modes: whether catalog prices already include tax
+const (
+	ModeExclusive = "exclusive"
+	ModeInclusive = "inclusive"
+)
+
+// ErrInvalidRate is returned for rates that are not decimal percentages between 0 and 100
+var ErrInvalidRate = errors.New("invalid tax rate")
+
+// ValidMode reports whether mode is a known pricing mode
+func ValidMode(mode string) bool {
+	return mode == ModeExclusive || mode == ModeInclusive
+}
+
+// ParseRate reads a percentage such as "19" or "8.875". Zero is allowed, so a rule can
+// exempt a category from a tax that applies to everything else.
+func ParseRate(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasPrefix(s, ".") || strings.HasSuffix(s, ".") || strings.Count(s, ".") > 1 {
+		return nil, ErrInvalidRate
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && r != '.' {
+			return nil, ErrInvalidRate
+		}
+	}
+
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Cmp(big.NewRat(100, 1)) > 0 {
+		return nil, ErrInvalidRate
+	}
+	return rate, nil
+}
+
+// FormatRate formats a percentage without trailing zeros, e.g. "8.875"
+func FormatRate(rate *big.Rat) string {
+	s := strings.TrimRight(rate.FloatString(6), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Rule is a tax of Rate percent on goods shipped to Country. Region and CategoryID narrow it
+// to one region of the country and to a category including its subcategories.
+type Rule struct {
+	Name       string
+	Country    string
+	Region     string
+	CategoryID *uint
+	Rate       *big.Rat
+}
+
+// Destination is where an order is shipped
+type Destination struct {
+	Country string
+	Region  string
+}
+
+// Item is a taxable amount, such as an order line
+type Item struct {
+	// CategoryPath is the item's category followed by its ancestors, nearest first
+	CategoryPath []uint
+	Amount       money.Money
+}
+
+// Line is the tax collected under one rule name and rate
+type Line struct {
+	Name   string
+	Rate   *big.Rat
+	Amount money.Money
+}
+
+// Breakdown is the result of a calculation. Subtotal excludes tax, Total includes it.
+type Breakdown struct {
+	Subtotal money.Money
+	Lines    []Line
+	Tax      money.Money
+	Total    money.Money
+}
+
+// Calculate taxes items, which must all be in currency. For every rule name the most specific
+// matching rule applies to an item: a rule for the item's category beats one for a parent
+// category, which beats one without a category; then a rule for the region beats one for the
+// whole country. Rules with different names add up, e.g. a federal and a regional sales tax.
+//
+// In exclusive mode the amounts are net and tax is added on top. In inclusive mode they are
+// gross and the tax contained in them is split out. Each line is rounded once.
+func Calculate(currency string, items []Item, dest Destination, rules []Rule, mode string) Breakdown {
+	type key struct {
+		name string
+		rate string
+	}
+	shares := make(map[key]*big.Rat)
+	rates := make(map[key]*big.Rat)
+
+	gross := money.New(0, currency)
+	for _, item := range items {
+		gross = gross.Add(item.Amount)
+
+		applied := applicableRules(item, dest, rules)
+		divisor := big.NewRat(1, 1)
+		if mode == ModeInclusive {
+			for _, rule := range applied {
+				divisor.Add(divisor, percent(rule.Rate))
+			}
+		}
+
+		for _, rule := range applied {
+			if rule.Rate.Sign() == 0 {
+				continue
+			}
+			// exclusive: amount * rate; inclusive: amount * rate / (1 + sum of rates)
+			share := new(big.Rat).SetInt64(item.Amount.Amount)
+			share.Mul(share, percent(rule.Rate))
+			share.Quo(share, divisor)
+
+			k := key{rule.Name, FormatRate(rule.Rate)}
+			if shares[k] == nil {
+				shares[k] = new(big.Rat)
+				rates[k] = rule.Rate
+			}
+			shares[k].Add(shares[k], share)
+		}
+	}
+
+	keys := make([]key, 0, len(shares))
+	for k := range shares {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return rates[keys[i]].Cmp(rates[keys[j]]) > 0
+	})
+
+	breakdown := Breakdown{Lines: []Line{}, Tax: money.New(0, currency)}
+	for _, k := range keys {
+		amount := money.FromRat(shares[k], currency)
+		breakdown.Lines = append(breakdown.Lines, Line{Name: k.name, Rate: rates[k], Amount: amount})
+		breakdown.Tax = breakdown.Tax.Add(amount)
+	}
+
+	if mode == ModeInclusive {
+		breakdown.Total = gross
+		breakdown.Subtotal = gross.Sub(breakdown.Tax)
+	} else {
+		breakdown.Subtotal = gross
+		breakdown.Total = gross.Add(breakdown.Tax)
+	}
+	return breakdown
+}
+
+// applicableRules picks the most specific matching rule of every name
+func applicableRules(item Item, dest Destination, rules []Rule) []Rule {
+	best := make(map[string]Rule)
+	bestRank := make(map[string]int)
+	var names []string
+
+	for _, rule := range rules {
+		rank, ok := specificity(rule, item, dest)
+		if !ok {
+			continue
+		}
+		current, seen := bestRank[rule.Name]
+		if !seen {
+			names = append(names, rule.Name)
+		}
+		if !seen || rank > current {
+			best[rule.Name] = rule
+			bestRank[rule.Name] = rank
+		}
+	}
+
+	applied := make([]Rule, 0, len(names))
+	for _, name := range names {
+		applied = append(applied, best[name])
+	}
+	return applied
+}
+
+// specificity reports whether rule applies to item and how specific it is; higher wins
+func specificity(rule Rule, item Item, dest Destination) (int, bool) {
+	if !strings.EqualFold(rule.Country, dest.Country) {
+		return 0, false
+	}
+	if rule.Region != "" && !strings.EqualFold(rule.Region, dest.Region) {
+		return 0, false
+	}
+
+	// Categories outrank regions: two points per level of category nearness, one for the region
+	rank := 0
+	if rule.CategoryID != nil {
+		depth := -1
+		for i, id := range item.CategoryPath {
+			if id == *rule.CategoryID {
+				depth = i
+				break
+			}
+		}
+		if depth < 0 {
+			return 0, false
+		}
+		rank += 2 * (len(item.CategoryPath) - depth)
+	}
+	if rule.Region != "" {
+		rank++
+	}
+	return rank, true
+}
+
+func percent(rate *big.Rat) *big.Rat {
+	return new(big.Rat).Quo(rate, big.NewRat(100, 1))
+}
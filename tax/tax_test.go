@@ -0,0 +1,102 @@
+package tax
+
+import (
+	"go-fiber-api/money"
+	"math/big"
+	"testing"
+)
+
+func rule(name, country, region string, categoryID uint, rate string) Rule {
+	r := Rule{Name: name, Country: country, Region: region, Rate: mustRate(rate)}
+	if categoryID != 0 {
+		r.CategoryID = &categoryID
+	}
+	return r
+}
+
+func mustRate(s string) *big.Rat {
+	rate, err := ParseRate(s)
+	if err != nil {
+		panic(err)
+	}
+	return rate
+}
+
+func usd(amount string) money.Money {
+	return money.MustParse(amount, "USD")
+}
+
+func TestCalculateExclusiveStacksTaxesByName(t *testing.T) {
+	rules := []Rule{
+		rule("GST", "CA", "", 0, "5"),
+		rule("PST", "CA", "BC", 0, "7"),
+		rule("PST", "CA", "ON", 0, "8"),
+	}
+	items := []Item{{Amount: usd("100.00")}, {Amount: usd("19.99")}}
+
+	b := Calculate("USD", items, Destination{Country: "ca", Region: "bc"}, rules, ModeExclusive)
+	if len(b.Lines) != 2 || b.Lines[0].Name != "GST" || b.Lines[1].Name != "PST" || FormatRate(b.Lines[1].Rate) != "7" {
+		t.Fatalf("Expected GST 5 and PST 7 lines, got %+v", b.Lines)
+	}
+	// 5% of 119.99 = 5.9995, 7% = 8.3993
+	if b.Lines[0].Amount != usd("6.00") || b.Lines[1].Amount != usd("8.40") {
+		t.Errorf("Expected 6.00 and 8.40, got %s and %s", b.Lines[0].Amount, b.Lines[1].Amount)
+	}
+	if b.Subtotal != usd("119.99") || b.Tax != usd("14.40") || b.Total != usd("134.39") {
+		t.Errorf("Unexpected totals %s + %s = %s", b.Subtotal, b.Tax, b.Total)
+	}
+}
+
+func TestCalculatePrefersMostSpecificRule(t *testing.T) {
+	const food, bread = 1, 2
+	rules := []Rule{
+		rule("VAT", "DE", "", 0, "19"),
+		rule("VAT", "DE", "BY", 0, "20"),
+		rule("VAT", "DE", "", food, "7"),
+		rule("VAT", "DE", "", bread, "0"),
+	}
+	items := []Item{
+		{CategoryPath: []uint{3}, Amount: usd("10.00")},
+		{CategoryPath: []uint{food}, Amount: usd("10.00")},
+		{CategoryPath: []uint{bread, food}, Amount: usd("10.00")},
+		{CategoryPath: []uint{4, food}, Amount: usd("10.00")},
+	}
+
+	b := Calculate("USD", items, Destination{Country: "DE", Region: "BY"}, rules, ModeExclusive)
+	if len(b.Lines) != 2 {
+		t.Fatalf("Expected a 20%% and a 7%% line without the zero rate, got %+v", b.Lines)
+	}
+	if FormatRate(b.Lines[0].Rate) != "20" || b.Lines[0].Amount != usd("2.00") {
+		t.Errorf("Expected the regional rate on the uncategorized item, got %+v", b.Lines[0])
+	}
+	if FormatRate(b.Lines[1].Rate) != "7" || b.Lines[1].Amount != usd("1.40") {
+		t.Errorf("Expected the food rate on food and its other subcategory, got %+v", b.Lines[1])
+	}
+}
+
+func TestCalculateInclusiveSplitsOutTax(t *testing.T) {
+	rules := []Rule{rule("VAT", "GB", "", 0, "20")}
+	items := []Item{{Amount: usd("12.00")}, {Amount: usd("0.99")}}
+
+	b := Calculate("USD", items, Destination{Country: "GB"}, rules, ModeInclusive)
+	// 12.99 / 1.2 = 10.825, so the tax is 2.165
+	if b.Total != usd("12.99") || b.Tax != usd("2.17") || b.Subtotal != usd("10.82") {
+		t.Errorf("Unexpected totals %s + %s = %s", b.Subtotal, b.Tax, b.Total)
+	}
+
+	b = Calculate("USD", items, Destination{Country: "US"}, rules, ModeInclusive)
+	if len(b.Lines) != 0 || b.Total != usd("12.99") || b.Subtotal != usd("12.99") {
+		t.Errorf("Expected no tax outside the rule's country, got %+v", b)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, s := range []string{"", "-1", "100.5", "1e2", ".5", "5.", "1/3", "abc"} {
+		if _, err := ParseRate(s); err == nil {
+			t.Errorf("Expected rate %q to be rejected", s)
+		}
+	}
+	if rate, err := ParseRate("8.8750"); err != nil || FormatRate(rate) != "8.875" {
+		t.Errorf("Expected 8.875, got %v %v", rate, err)
+	}
+}
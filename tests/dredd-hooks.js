@@ -12,6 +12,7 @@ const CONFIG = {
   OPENAPI_SCHEMA_PATH: process.env.OPENAPI_SCHEMA_PATH || 'schemas/api-schema.yaml',
   SERVER_PORT: process.env.SERVER_PORT || '3000',
   UNIQUE_EMAIL_SUFFIX: process.env.UNIQUE_EMAIL_SUFFIX || '@example.com',
+  CURRENCY: process.env.CURRENCY || 'USD',
   
   // Authentication Configuration
   AUTH_TYPE: process.env.AUTH_TYPE || 'bearer', // bearer, apikey, basic, oauth2, custom
@@ -249,6 +250,14 @@ function generateTestData(method, uri, statusCode, transactionName) {
     const testData = {};
     
     Object.entries(properties).forEach(([fieldName, fieldSchema]) => {
+      if (fieldSchema.$ref === '#/components/schemas/Money') {
+        // Money amounts are decimal strings with a currency
+        const invalid = statusCode === '400' || (transactionName && transactionName.includes('400'));
+        const amount = invalid ? -1 : generateValidValue('number', undefined, fieldName);
+        testData[fieldName] = { amount: amount.toFixed(2), currency: CONFIG.CURRENCY };
+        return;
+      }
+
       const fieldType = fieldSchema.type;
       const fieldFormat = fieldSchema.format;
       
@@ -0,0 +1,72 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestVerifyValidatesClaims(t *testing.T) {
+	key, _ := GenerateEd25519Key()
+	ks, _ := NewKeySet(key)
+
+	issued := time.Unix(1700000000, 0)
+	sign := func(modify func(*Claims)) string {
+		claims := Claims{RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "7",
+			Issuer:    "api",
+			Audience:  jwt.ClaimStrings{"clients"},
+			IssuedAt:  jwt.NewNumericDate(issued),
+			NotBefore: jwt.NewNumericDate(issued),
+			ExpiresAt: jwt.NewNumericDate(issued.Add(time.Hour)),
+		}}
+		if modify != nil {
+			modify(&claims)
+		}
+		tokenString, err := ks.Sign(claims)
+		if err != nil {
+			t.Fatalf("Sign returned error: %v", err)
+		}
+		return tokenString
+	}
+	at := func(now time.Time) Validation {
+		return Validation{Issuer: "api", Audience: "clients", Leeway: 30 * time.Second, Now: func() time.Time { return now }}
+	}
+
+	claims, err := ks.Verify(sign(nil), at(issued.Add(time.Minute)))
+	if err != nil {
+		t.Fatalf("Expected valid token, got %v", err)
+	}
+	if id, err := claims.UserID(); err != nil || id != 7 {
+		t.Errorf("Expected user ID 7, got %d (%v)", id, err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		now    time.Time
+		expect error
+	}{
+		{"expired within leeway", sign(nil), issued.Add(time.Hour + 20*time.Second), nil},
+		{"expired", sign(nil), issued.Add(time.Hour + time.Minute), ErrTokenExpired},
+		{"issued in the future within leeway", sign(nil), issued.Add(-20 * time.Second), nil},
+		{"not yet valid", sign(nil), issued.Add(-time.Minute), ErrTokenNotYetValid},
+		{"wrong issuer", sign(func(c *Claims) { c.Issuer = "other" }), issued, ErrInvalidIssuer},
+		{"wrong audience", sign(func(c *Claims) { c.Audience = jwt.ClaimStrings{"other"} }), issued, ErrInvalidAudience},
+		{"restricted purpose", sign(func(c *Claims) { c.Purpose = "mfa" }), issued, ErrWrongPurpose},
+		{"missing expiry", sign(func(c *Claims) { c.ExpiresAt = nil }), issued, ErrTokenMalformed},
+		{"garbage", "not.a.token", issued, ErrTokenMalformed},
+	}
+	for _, tt := range tests {
+		if _, err := ks.Verify(tt.token, at(tt.now)); err != tt.expect {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expect, err)
+		}
+	}
+
+	other, _ := GenerateEd25519Key()
+	otherSet, _ := NewKeySet(other)
+	if _, err := otherSet.Verify(sign(nil), at(issued)); err != ErrTokenSignatureInvalid {
+		t.Errorf("Expected ErrTokenSignatureInvalid for an unknown key, got %v", err)
+	}
+}
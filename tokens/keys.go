@@ -0,0 +1,214 @@
+// Package tokens signs and verifies JWTs with asymmetric keys identified by a kid header.
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrUnsupportedKey is returned for keys other than RSA and Ed25519
+var ErrUnsupportedKey = errors.New("tokens: unsupported key type, expected RSA or Ed25519")
+
+// Key is a signing or verification key. Keys loaded from a public key have no Private part
+// and can only verify tokens.
+type Key struct {
+	// ID is published as the kid header and in the JWKS; it is the RFC 7638 thumbprint of the public key
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// NewKey wraps an RSA or Ed25519 private or public key
+func NewKey(key interface{}) (*Key, error) {
+	k := &Key{}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		k.Private, k.Public = key, &key.PublicKey
+	case *rsa.PublicKey:
+		k.Public = key
+	case ed25519.PrivateKey:
+		k.Private, k.Public = key, key.Public()
+	case ed25519.PublicKey:
+		k.Public = key
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	switch k.Public.(type) {
+	case *rsa.PublicKey:
+		k.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.Method = jwt.SigningMethodEdDSA
+	}
+
+	id, err := thumbprint(k.JWK())
+	if err != nil {
+		return nil, err
+	}
+	k.ID = id
+	return k, nil
+}
+
+// GenerateEd25519Key returns a new random Ed25519 signing key
+func GenerateEd25519Key() (*Key, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(private)
+}
+
+// DeriveSecret derives a symmetric secret for label from the private key, so other signatures
+// can be keyed without configuring a separate secret. It fails for verification-only keys.
+func (k *Key) DeriveSecret(label string) ([]byte, error) {
+	if k.Private == nil {
+		return nil, errors.New("tokens: cannot derive a secret from a public key")
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, der)
+	mac.Write([]byte(label))
+	return mac.Sum(nil), nil
+}
+
+// LoadKeyFile reads a PEM-encoded key. PKCS#8 and PKCS#1 private keys as well as
+// PKIX and PKCS#1 public keys are accepted.
+func LoadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParseKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// ParseKeyPEM parses the first PEM block in data as a private or public key
+func ParseKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("tokens: no PEM block found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("tokens: unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(key)
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set as served from /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public part of the key
+func (k *Key) JWK() JWK {
+	jwk := JWK{Use: "sig", Kid: k.ID}
+	if k.Method != nil {
+		jwk.Alg = k.Method.Alg()
+	}
+
+	switch public := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// PublicKey decodes an RSA or Ed25519 JWK, e.g. one published by another issuer
+func (jwk JWK) PublicKey() (*Key, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return NewKey(&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())})
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return NewKey(ed25519.PublicKey(x))
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// thumbprint computes the RFC 7638 thumbprint from the required members of the JWK
+func thumbprint(jwk JWK) (string, error) {
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		// encoding/json sorts map keys, giving the lexicographic order RFC 7638 requires
+		members = map[string]string{"e": jwk.E, "kty": jwk.Kty, "n": jwk.N}
+	case "OKP":
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X}
+	default:
+		return "", ErrUnsupportedKey
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,80 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var validMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+// ErrUnknownKey is returned when a token's kid does not match any verification key
+var ErrUnknownKey = errors.New("tokens: unknown signing key")
+
+// KeySet signs tokens with a single active key and verifies tokens signed by any of its keys.
+// Keeping retired keys in the set lets tokens issued before a rotation stay valid until they expire.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	order   []*Key
+}
+
+// NewKeySet creates a key set that signs with signing and additionally accepts tokens
+// signed by any of the verification keys
+func NewKeySet(signing *Key, verification ...*Key) (*KeySet, error) {
+	if signing == nil || signing.Private == nil {
+		return nil, errors.New("tokens: signing key must include a private key")
+	}
+
+	ks := &KeySet{signing: signing, keys: map[string]*Key{}}
+	for _, key := range append([]*Key{signing}, verification...) {
+		if _, ok := ks.keys[key.ID]; ok {
+			continue
+		}
+		ks.keys[key.ID] = key
+		ks.order = append(ks.order, key)
+	}
+	return ks, nil
+}
+
+// SigningKey returns the key new tokens are signed with
+func (ks *KeySet) SigningKey() *Key {
+	return ks.signing
+}
+
+// Sign returns a token for claims signed with the active key and carrying its kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.Private)
+}
+
+// Parse verifies the token's signature against the key named by its kid header and decodes
+// its claims. Only asymmetric algorithms are accepted and the algorithm must match the key,
+// so a public key can never be used as an HMAC secret.
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	options = append([]jwt.ParserOption{jwt.WithValidMethods(validMethods)}, options...)
+	return jwt.ParseWithClaims(tokenString, claims, ks.keyFunc, options...)
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("tokens: unexpected signing method %s for key %s", token.Method.Alg(), kid)
+	}
+	return key.Public, nil
+}
+
+// JWKS returns the public keys of the set for publication
+func (ks *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(ks.order))}
+	for _, key := range ks.order {
+		jwks.Keys = append(jwks.Keys, key.JWK())
+	}
+	return jwks
+}
@@ -0,0 +1,154 @@
+package tokens
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func generateRSAKey(t *testing.T) *Key {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey returned error: %v", err)
+	}
+	key, err := NewKey(private)
+	if err != nil {
+		t.Fatalf("NewKey returned error: %v", err)
+	}
+	return key
+}
+
+func TestSignAndParseRoundTrip(t *testing.T) {
+	ed, err := GenerateEd25519Key()
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key returned error: %v", err)
+	}
+
+	for _, key := range []*Key{ed, generateRSAKey(t)} {
+		ks, err := NewKeySet(key)
+		if err != nil {
+			t.Fatalf("NewKeySet returned error: %v", err)
+		}
+
+		tokenString, err := ks.Sign(jwt.RegisteredClaims{
+			Subject:   "42",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		})
+		if err != nil {
+			t.Fatalf("%s: Sign returned error: %v", key.Method.Alg(), err)
+		}
+
+		claims := &jwt.RegisteredClaims{}
+		token, err := ks.Parse(tokenString, claims)
+		if err != nil || !token.Valid {
+			t.Fatalf("%s: Parse returned error: %v", key.Method.Alg(), err)
+		}
+		if token.Header["kid"] != key.ID || claims.Subject != "42" {
+			t.Errorf("%s: unexpected kid %v or subject %q", key.Method.Alg(), token.Header["kid"], claims.Subject)
+		}
+	}
+}
+
+func TestRotatedKeysStillVerify(t *testing.T) {
+	old, _ := GenerateEd25519Key()
+	current := generateRSAKey(t)
+
+	before, _ := NewKeySet(old)
+	tokenString, err := before.Sign(jwt.RegisteredClaims{Subject: "1"})
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+
+	after, _ := NewKeySet(current, old)
+	if _, err := after.Parse(tokenString, &jwt.RegisteredClaims{}); err != nil {
+		t.Errorf("Expected token signed by a retired key to verify, got %v", err)
+	}
+
+	withoutOld, _ := NewKeySet(current)
+	if _, err := withoutOld.Parse(tokenString, &jwt.RegisteredClaims{}); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey once the key is removed, got %v", err)
+	}
+
+	if jwks := after.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].Kid != current.ID || jwks.Keys[1].Kty != "OKP" {
+		t.Errorf("Unexpected JWKS %+v", jwks)
+	}
+}
+
+func TestParseRejectsHMACWithPublicKey(t *testing.T) {
+	key := generateRSAKey(t)
+	ks, _ := NewKeySet(key)
+
+	// Classic algorithm confusion: an HS256 token keyed with the published RSA modulus
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "1"})
+	token.Header["kid"] = key.ID
+	tokenString, _ := token.SignedString([]byte(key.JWK().N))
+
+	if _, err := ks.Parse(tokenString, &jwt.RegisteredClaims{}); err == nil {
+		t.Error("Expected HS256 token to be rejected")
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	private, _ := rsa.GenerateKey(rand.Reader, 2048)
+	der, _ := x509.MarshalPKCS8PrivateKey(private)
+	publicDER, _ := x509.MarshalPKIXPublicKey(&private.PublicKey)
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "private.pem")
+	publicPath := filepath.Join(dir, "public.pem")
+	os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644)
+
+	signing, err := LoadKeyFile(privatePath)
+	if err != nil {
+		t.Fatalf("LoadKeyFile returned error for private key: %v", err)
+	}
+	verification, err := LoadKeyFile(publicPath)
+	if err != nil {
+		t.Fatalf("LoadKeyFile returned error for public key: %v", err)
+	}
+
+	if signing.ID != verification.ID {
+		t.Errorf("Expected private and public key to share a kid, got %s and %s", signing.ID, verification.ID)
+	}
+	if verification.Private != nil {
+		t.Error("Expected public key to have no private part")
+	}
+	if _, err := NewKeySet(verification); err == nil {
+		t.Error("Expected NewKeySet to reject a public-only signing key")
+	}
+}
+
+func TestDeriveSecret(t *testing.T) {
+	key, _ := GenerateEd25519Key()
+	other, _ := GenerateEd25519Key()
+
+	first, err := key.DeriveSecret("email-tokens")
+	if err != nil {
+		t.Fatalf("DeriveSecret returned error: %v", err)
+	}
+	again, _ := key.DeriveSecret("email-tokens")
+	otherLabel, _ := key.DeriveSecret("other")
+	otherKey, _ := other.DeriveSecret("email-tokens")
+	if len(first) != 32 || !bytes.Equal(first, again) {
+		t.Errorf("Expected a stable 32-byte secret, got %x and %x", first, again)
+	}
+	if bytes.Equal(first, otherLabel) || bytes.Equal(first, otherKey) {
+		t.Error("Expected different labels and keys to derive different secrets")
+	}
+
+	public, _ := NewKey(key.Public)
+	if _, err := public.DeriveSecret("email-tokens"); err == nil {
+		t.Error("Expected deriving a secret from a public key to fail")
+	}
+}
@@ -0,0 +1,53 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238 appendix B (SHA-1, truncated to 6 digits)
+func TestCodeMatchesRFC6238Vectors(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, v := range vectors {
+		code, err := Code(secret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code returned error: %v", err)
+		}
+		if code != v.code {
+			t.Errorf("At %d expected code %s, got %s", v.unix, v.code, code)
+		}
+	}
+}
+
+func TestValidateAcceptsAdjacentStepsOnly(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret returned error: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	code, _ := Code(secret, Step(now)-1)
+
+	if step, ok := Validate(secret, code, now); !ok || step != Step(now)-1 {
+		t.Errorf("Expected code of the previous step to be accepted, got step %d ok %v", step, ok)
+	}
+	if _, ok := Validate(secret, code, now.Add(2*Period*time.Second)); ok {
+		t.Error("Expected code from three steps ago to be rejected")
+	}
+	if _, ok := Validate(secret, "12345", now); ok {
+		t.Error("Expected code with the wrong length to be rejected")
+	}
+}